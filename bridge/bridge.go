@@ -0,0 +1,222 @@
+// Package bridge mirrors messages between paired channels on different
+// platforms, such as a Discord channel and a Revolt channel.
+//
+// New messages are posted to the paired channel under the author's name and
+// avatar, using webhooks on Discord and masquerade on Revolt. Edits,
+// deletions and added reactions follow, and replies point at the copy of
+// the message replied to. The bridge ignores the messages it posts itself,
+// so paired channels do not echo each other.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// timeout bounds the store and platform calls made for one event.
+const timeout = 30 * time.Second
+
+// Bridge relays messages between paired channels. It is safe for concurrent
+// use.
+type Bridge struct {
+	bot   *whiskercat.Bot
+	store Store
+
+	mu    sync.RWMutex
+	pairs map[types.ChannelRef][]types.ChannelRef
+
+	remove []func()
+}
+
+// New creates a bridge relaying the bot's events, which keeps its mirrors
+// in store. Channels are bridged once they are paired with Pair.
+func New(bot *whiskercat.Bot, store Store) *Bridge {
+	b := &Bridge{
+		bot:   bot,
+		store: store,
+		pairs: make(map[types.ChannelRef][]types.ChannelRef),
+	}
+	b.remove = []func(){
+		bot.OnMessageCreate(b.onCreate),
+		bot.OnMessageUpdate(b.onUpdate),
+		bot.OnMessageDelete(b.onDelete),
+		bot.OnReactionAdd(b.onReaction),
+	}
+	return b
+}
+
+// Close stops relaying. Mirrors already made are kept in the store.
+func (b *Bridge) Close() {
+	for _, remove := range b.remove {
+		remove()
+	}
+}
+
+// Pair bridges two channels, which are usually on different platforms.
+// A channel can be paired with several others; messages are relayed to the
+// channels it is paired with directly.
+func (b *Bridge) Pair(x, y types.ChannelRef) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !slices.Contains(b.pairs[x], y) {
+		b.pairs[x] = append(b.pairs[x], y)
+		b.pairs[y] = append(b.pairs[y], x)
+	}
+}
+
+// Unpair stops bridging two channels.
+func (b *Bridge) Unpair(x, y types.ChannelRef) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pairs[x] = slices.DeleteFunc(b.pairs[x], func(c types.ChannelRef) bool { return c == y })
+	b.pairs[y] = slices.DeleteFunc(b.pairs[y], func(c types.ChannelRef) bool { return c == x })
+}
+
+// Paired returns the channels a channel is paired with.
+func (b *Bridge) Paired(c types.ChannelRef) []types.ChannelRef {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return slices.Clone(b.pairs[c])
+}
+
+func (b *Bridge) onCreate(evt types.Event, msg types.MessageCallback) {
+	targets := b.Paired(evt.ChannelRef())
+	if evt.Self || len(targets) == 0 || msg.Content == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	source := message(evt, msg.ID)
+	masquerade := &types.Masquerade{Name: msg.Author.Username, Avatar: msg.Author.Avatar}
+	for _, target := range targets {
+		send := types.MessageSend{Content: msg.Content, Masquerade: masquerade}
+		if msg.ReplyTo != "" {
+			if reply, err := b.counterpart(ctx, message(evt, msg.ReplyTo), target); err == nil {
+				send.ReplyTo = reply.ID
+			}
+		}
+
+		sent, err := b.bot.Send(target, send)
+		if err != nil {
+			log.Printf("Bridge failed to relay %s to %s: %v\n", source, target, err)
+			continue
+		}
+		// Messages without IDs, such as IRC's, cannot be edited or deleted,
+		// so there is nothing to remember.
+		if msg.ID == "" || sent.ID == "" {
+			continue
+		}
+		mirror := Mirror{Source: source, Message: sent.Ref(), ReplyTo: send.ReplyTo, Masquerade: masquerade}
+		if err := b.store.Put(ctx, mirror); err != nil {
+			log.Printf("Bridge failed to save mirror of %s: %v\n", source, err)
+		}
+	}
+}
+
+// onUpdate relays edits. Updates that are not edits, such as Discord adding
+// link previews, are skipped.
+func (b *Bridge) onUpdate(evt types.Event, msg types.MessageCallback) {
+	if evt.Self || msg.EditedAt.IsZero() || msg.Content == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	mirrors, err := b.store.Mirrors(ctx, message(evt, msg.ID))
+	if err != nil {
+		log.Printf("Bridge failed to look up mirrors of %s: %v\n", message(evt, msg.ID), err)
+		return
+	}
+	for _, m := range mirrors {
+		send := types.MessageSend{Content: msg.Content, ReplyTo: m.ReplyTo, Masquerade: m.Masquerade}
+		if _, err := b.bot.Edit(m.Message, send); err != nil {
+			log.Printf("Bridge failed to edit %s: %v\n", m.Message, err)
+		}
+	}
+}
+
+// onDelete deletes the mirrors of deleted messages. A deleted mirror is
+// only forgotten; the original stays.
+func (b *Bridge) onDelete(evt types.Event, msg types.MessageCallback) {
+	if msg.ID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	deleted := message(evt, msg.ID)
+	mirrors, err := b.store.Mirrors(ctx, deleted)
+	if err != nil {
+		log.Printf("Bridge failed to look up mirrors of %s: %v\n", deleted, err)
+		return
+	}
+	for _, m := range mirrors {
+		if err := b.bot.Delete(m.Message); err != nil {
+			log.Printf("Bridge failed to delete %s: %v\n", m.Message, err)
+		}
+	}
+	if err := b.store.Delete(ctx, deleted); err != nil {
+		log.Printf("Bridge failed to forget %s: %v\n", deleted, err)
+	}
+}
+
+// onReaction adds reactions to every other copy of a message. Custom emoji
+// only exist on their own platform and are not relayed, and neither are
+// removed reactions, since the bot can only remove its own.
+func (b *Bridge) onReaction(evt types.Event, reaction types.ReactionCallback) {
+	targets := b.Paired(evt.ChannelRef())
+	if evt.Self || len(targets) == 0 || reaction.Emoji.Custom() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	reacted := message(evt, reaction.MessageID)
+	for _, target := range targets {
+		twin, err := b.counterpart(ctx, reacted, target)
+		if err != nil {
+			continue
+		}
+		if err := b.bot.React(twin, reaction.Emoji.Name); err != nil {
+			log.Printf("Bridge failed to react to %s: %v\n", twin, err)
+		}
+	}
+}
+
+// counterpart returns the copy of msg in target: the original if msg is a
+// mirror from target, or one of the original's mirrors.
+func (b *Bridge) counterpart(ctx context.Context, msg types.MessageRef, target types.ChannelRef) (types.MessageRef, error) {
+	source, err := b.store.Source(ctx, msg)
+	if errors.Is(err, ErrNotFound) {
+		source = msg
+	} else if err != nil {
+		return types.MessageRef{}, err
+	}
+	if source.Channel == target {
+		return source, nil
+	}
+
+	mirrors, err := b.store.Mirrors(ctx, source)
+	if err != nil {
+		return types.MessageRef{}, err
+	}
+	for _, m := range mirrors {
+		if m.Message.Channel == target {
+			return m.Message, nil
+		}
+	}
+	return types.MessageRef{}, ErrNotFound
+}
+
+// message returns a reference to a message in the event's channel.
+func message(evt types.Event, id string) types.MessageRef {
+	return types.MessageRef{Channel: evt.ChannelRef(), ID: id}
+}
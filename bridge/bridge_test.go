@@ -0,0 +1,217 @@
+package bridge_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/bridge"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/luvixsocial/whiskercat/whiskercattest"
+)
+
+// anonymous is a fake platform whose messages have no IDs, like IRC.
+type anonymous struct {
+	*whiskercattest.Platform
+}
+
+func (p anonymous) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	sent, err := p.Platform.Send(channelID, msg)
+	if sent != nil {
+		sent.ID = ""
+	}
+	return sent, err
+}
+
+// inject delivers a message without an ID, as the IRC adapter does.
+func (p anonymous) inject(channelID, content string) {
+	p.Emit(types.Event{
+		Type:      types.MessageCreate,
+		ChannelID: channelID,
+		Data:      types.MessageCallback{ChannelID: channelID, Content: content, Author: whiskercattest.User("carol")},
+	})
+}
+
+// newBot returns a bot running handlers inline on the given platforms.
+func newBot(platforms ...platform.Platform) *whiskercat.Bot {
+	return whiskercat.NewWithPlatforms(platforms, whiskercat.WithDispatch(whiskercat.DispatchConfig{}))
+}
+
+func find(t *testing.T, p *whiskercattest.Platform, kind whiskercattest.ActionKind) whiskercattest.Action {
+	t.Helper()
+	for _, a := range p.Actions() {
+		if a.Kind == kind {
+			return a
+		}
+	}
+	t.Fatalf("no %s on %s; actions: %+v", kind, p.Name(), p.Actions())
+	return whiskercattest.Action{}
+}
+
+func TestRelay(t *testing.T) {
+	alpha, beta := whiskercattest.NewPlatform("Alpha"), whiskercattest.NewPlatform("Beta")
+	b := bridge.New(newBot(alpha, beta), bridge.NewMemoryStore(0))
+	defer b.Close()
+	b.Pair(types.ChannelRef{Platform: "alpha", ID: "a"}, types.ChannelRef{Platform: "beta", ID: "b"})
+
+	alice := whiskercattest.User("alice")
+	alice.Avatar = "https://example.com/alice.png"
+	original := alpha.InjectMessage("a", alice, "hello")
+
+	sent := find(t, beta, whiskercattest.ActionSend)
+	if sent.ChannelID != "b" || sent.Message.Content != "hello" {
+		t.Fatalf("relayed %q to %s, want %q to b", sent.Message.Content, sent.ChannelID, "hello")
+	}
+	if m := sent.Message.Masquerade; m == nil || m.Name != "alice" || m.Avatar != alice.Avatar {
+		t.Fatalf("masquerade = %+v, want alice and her avatar", m)
+	}
+
+	// A reply on the other side points at the original.
+	alpha.Reset()
+	reply := &whiskercattest.Message{ID: sent.MessageID, ChannelID: "b"}
+	beta.InjectReply(reply, whiskercattest.User("bob"), "welcome")
+	if got := find(t, alpha, whiskercattest.ActionSend); got.ReplyTo != original.ID {
+		t.Errorf("reply relayed as reply to %q, want %q", got.ReplyTo, original.ID)
+	}
+
+	beta.Reset()
+	alpha.InjectEdit(original, "hello there")
+	if edit := find(t, beta, whiskercattest.ActionEdit); edit.MessageID != sent.MessageID || edit.Message.Content != "hello there" {
+		t.Errorf("edit = %s %q, want %s %q", edit.MessageID, edit.Message.Content, sent.MessageID, "hello there")
+	}
+
+	alpha.InjectReaction("a", original.ID, whiskercattest.User("bob"), "👍", false)
+	if react := find(t, beta, whiskercattest.ActionReact); react.MessageID != sent.MessageID || react.Emoji != "👍" {
+		t.Errorf("reaction = %s %s, want 👍 on %s", react.MessageID, react.Emoji, sent.MessageID)
+	}
+
+	alpha.InjectDelete(original)
+	if del := find(t, beta, whiskercattest.ActionDelete); del.MessageID != sent.MessageID {
+		t.Errorf("deleted %s, want %s", del.MessageID, sent.MessageID)
+	}
+}
+
+func TestRelayIgnoresSelf(t *testing.T) {
+	alpha, beta := whiskercattest.NewPlatform("Alpha"), whiskercattest.NewPlatform("Beta")
+	b := bridge.New(newBot(alpha, beta), bridge.NewMemoryStore(0))
+	defer b.Close()
+	b.Pair(types.ChannelRef{Platform: "alpha", ID: "a"}, types.ChannelRef{Platform: "beta", ID: "b"})
+
+	alpha.Emit(types.Event{
+		Type:      types.MessageCreate,
+		ChannelID: "a",
+		Self:      true,
+		Data:      types.MessageCallback{ID: "1", ChannelID: "a", Content: "echo"},
+	})
+	if actions := beta.Actions(); len(actions) > 0 {
+		t.Errorf("relayed the bot's own message: %+v", actions)
+	}
+}
+
+func TestFileStoreReloadsAfterRelayWithoutIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mirrors.jsonl")
+	store, err := bridge.NewFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	irc, alpha := anonymous{whiskercattest.NewPlatform("IRC")}, whiskercattest.NewPlatform("Alpha")
+	b := bridge.New(newBot(irc, alpha), store)
+	b.Pair(types.ChannelRef{Platform: "irc", ID: "#chan"}, types.ChannelRef{Platform: "alpha", ID: "a"})
+
+	irc.inject("#chan", "from irc")
+	original := alpha.InjectMessage("a", whiskercattest.User("alice"), "to irc")
+	if n := len(irc.Actions()); n != 1 {
+		t.Fatalf("relayed %d messages to IRC, want 1", n)
+	}
+	if n := len(alpha.Actions()); n != 1 {
+		t.Fatalf("relayed %d messages from IRC, want 1", n)
+	}
+	b.Close()
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := bridge.NewFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("reloading after relaying messages without IDs: %v", err)
+	}
+	defer reloaded.Close()
+	source := types.MessageRef{Channel: types.ChannelRef{Platform: "alpha", ID: "a"}, ID: original.ID}
+	if mirrors, err := reloaded.Mirrors(context.Background(), source); err != nil || len(mirrors) != 0 {
+		t.Errorf("Mirrors(%s) = %v, %v; want none", source, mirrors, err)
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mirrors.jsonl")
+	store, err := bridge.NewFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept := bridge.Mirror{Source: ref("alpha", "a", "1"), Message: ref("beta", "b", "2")}
+	deleted := bridge.Mirror{Source: ref("alpha", "a", "3"), Message: ref("beta", "b", "4")}
+	for _, m := range []bridge.Mirror{kept, deleted} {
+		if err := store.Put(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Delete(ctx, deleted.Source); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reloaded, err := bridge.NewFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	if source, err := reloaded.Source(ctx, kept.Message); err != nil || source != kept.Source {
+		t.Errorf("Source(%s) = %s, %v; want %s", kept.Message, source, err, kept.Source)
+	}
+	if _, err := reloaded.Source(ctx, deleted.Message); !errors.Is(err, bridge.ErrNotFound) {
+		t.Errorf("Source(%s) of a deleted mirror: err = %v, want ErrNotFound", deleted.Message, err)
+	}
+}
+
+func TestFileStoreFailedWrite(t *testing.T) {
+	ctx := context.Background()
+	store, err := bridge.NewFileStore(filepath.Join(t.TempDir(), "mirrors.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := bridge.Mirror{Source: ref("alpha", "a", "1"), Message: ref("beta", "b", "2")}
+	if err := store.Put(ctx, kept); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	if err := store.Put(ctx, bridge.Mirror{Source: ref("alpha", "a", "3"), Message: ref("beta", "b", "4")}); err == nil {
+		t.Fatal("Put succeeded after the file was closed")
+	}
+	if _, err := store.Source(ctx, ref("beta", "b", "4")); !errors.Is(err, bridge.ErrNotFound) {
+		t.Errorf("failed Put is visible: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, kept.Source); err == nil {
+		t.Fatal("Delete succeeded after the file was closed")
+	}
+	if source, err := store.Source(ctx, kept.Message); err != nil || source != kept.Source {
+		t.Errorf("failed Delete is visible: Source = %s, %v", source, err)
+	}
+}
+
+func TestStoreRejectsMissingIDs(t *testing.T) {
+	mirror := bridge.Mirror{Source: ref("irc", "#chan", ""), Message: ref("alpha", "a", "1")}
+	if err := bridge.NewMemoryStore(0).Put(context.Background(), mirror); err == nil {
+		t.Error("MemoryStore.Put accepted a mirror of a message without an ID")
+	}
+}
+
+func ref(platform, channelID, id string) types.MessageRef {
+	return types.MessageRef{Channel: types.ChannelRef{Platform: platform, ID: channelID}, ID: id}
+}
@@ -0,0 +1,268 @@
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// ErrNotFound is returned by a Store for messages it has no record of.
+var ErrNotFound = errors.New("message not bridged")
+
+// Mirror is a copy of a message posted by the bridge.
+type Mirror struct {
+	Source     types.MessageRef  // The original message
+	Message    types.MessageRef  // The copy
+	ReplyTo    string            `json:",omitempty"` // Message the copy replies to, in the copy's channel
+	Masquerade *types.Masquerade `json:",omitempty"` // Name and avatar the copy was posted under
+}
+
+// validate rejects mirrors of or by messages without IDs, such as IRC
+// messages, which could never be looked up again.
+func (m Mirror) validate() error {
+	if m.Source.ID == "" || m.Message.ID == "" {
+		return fmt.Errorf("bridge: mirror %s of %s has no message ID", m.Message, m.Source)
+	}
+	return nil
+}
+
+// Store keeps the mirrors of bridged messages, so edits, deletions, replies
+// and reactions reach every copy. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Put records a mirror. Both messages must have IDs.
+	Put(ctx context.Context, mirror Mirror) error
+
+	// Source returns the message msg is a copy of, or ErrNotFound if msg
+	// is not a mirror.
+	Source(ctx context.Context, msg types.MessageRef) (types.MessageRef, error)
+
+	// Mirrors returns the copies of source, which may be none.
+	Mirrors(ctx context.Context, source types.MessageRef) ([]Mirror, error)
+
+	// Delete forgets msg: a source together with its mirrors, or a single
+	// mirror.
+	Delete(ctx context.Context, msg types.MessageRef) error
+}
+
+// MemoryStore keeps the mirrors of the most recently bridged messages in
+// memory. Older messages are forgotten, after which their edits and
+// deletions are no longer relayed.
+type MemoryStore struct {
+	// mu keeps the two caches consistent with each other.
+	mu      sync.Mutex
+	sources *cache.Cache[types.MessageRef, types.MessageRef] // By mirror
+	mirrors *cache.Cache[types.MessageRef, []Mirror]         // By source
+}
+
+// NewMemoryStore creates a MemoryStore remembering up to size messages. A
+// size of 0 selects cache.DefaultSize.
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{
+		sources: cache.New[types.MessageRef, types.MessageRef](size, -1),
+		mirrors: cache.New[types.MessageRef, []Mirror](size, -1),
+	}
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(_ context.Context, mirror Mirror) error {
+	if err := mirror.validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(mirror)
+	return nil
+}
+
+func (s *MemoryStore) put(mirror Mirror) {
+	mirrors, _ := s.mirrors.Get(mirror.Source)
+	mirrors = slices.DeleteFunc(slices.Clone(mirrors), func(m Mirror) bool { return m.Message == mirror.Message })
+	s.mirrors.Set(mirror.Source, append(mirrors, mirror))
+	s.sources.Set(mirror.Message, mirror.Source)
+}
+
+// Source implements Store.
+func (s *MemoryStore) Source(_ context.Context, msg types.MessageRef) (types.MessageRef, error) {
+	if source, ok := s.sources.Get(msg); ok {
+		return source, nil
+	}
+	return types.MessageRef{}, ErrNotFound
+}
+
+// Mirrors implements Store.
+func (s *MemoryStore) Mirrors(_ context.Context, source types.MessageRef) ([]Mirror, error) {
+	mirrors, _ := s.mirrors.Get(source)
+	return slices.Clone(mirrors), nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ context.Context, msg types.MessageRef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delete(msg)
+	return nil
+}
+
+func (s *MemoryStore) delete(msg types.MessageRef) {
+	if source, ok := s.sources.Get(msg); ok {
+		s.sources.Delete(msg)
+		mirrors, _ := s.mirrors.Get(source)
+		mirrors = slices.DeleteFunc(slices.Clone(mirrors), func(m Mirror) bool { return m.Message == msg })
+		if len(mirrors) == 0 {
+			s.mirrors.Delete(source)
+		} else {
+			s.mirrors.Set(source, mirrors)
+		}
+		return
+	}
+
+	mirrors, _ := s.mirrors.Get(msg)
+	for _, m := range mirrors {
+		s.sources.Delete(m.Message)
+	}
+	s.mirrors.Delete(msg)
+}
+
+// FileStore is a MemoryStore that also appends every change to a file, so
+// the mirrors survive restarts. The file is compacted when it is opened.
+type FileStore struct {
+	memory *MemoryStore
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// record is a line of a FileStore's file.
+type record struct {
+	Put    *Mirror           `json:",omitempty"`
+	Delete *types.MessageRef `json:",omitempty"`
+}
+
+// NewFileStore creates a FileStore remembering up to size messages in the
+// file at path, loading the mirrors already saved there. A size of 0 selects
+// cache.DefaultSize.
+func NewFileStore(path string, size int) (*FileStore, error) {
+	s := &FileStore{memory: NewMemoryStore(size)}
+	puts, err := s.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewrite the file with the mirrors that are still remembered, in the
+	// order they were made.
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, m := range puts {
+		if source, err := s.memory.Source(context.Background(), m.Message); err != nil || source != m.Source {
+			continue
+		}
+		if err := enc.Encode(record{Put: &m}); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	if s.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replays the file at path into memory and returns the mirrors it put.
+func (s *FileStore) load(path string) ([]Mirror, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var puts []Mirror
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			return nil, err
+		}
+		switch {
+		case r.Put != nil:
+			s.memory.put(*r.Put)
+			puts = append(puts, *r.Put)
+		case r.Delete != nil:
+			s.memory.delete(*r.Delete)
+		}
+	}
+	return puts, nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(ctx context.Context, mirror Mirror) error {
+	if err := mirror.validate(); err != nil {
+		return err
+	}
+	return s.append(record{Put: &mirror}, func() { s.memory.Put(ctx, mirror) })
+}
+
+// Source implements Store.
+func (s *FileStore) Source(ctx context.Context, msg types.MessageRef) (types.MessageRef, error) {
+	return s.memory.Source(ctx, msg)
+}
+
+// Mirrors implements Store.
+func (s *FileStore) Mirrors(ctx context.Context, source types.MessageRef) ([]Mirror, error) {
+	return s.memory.Mirrors(ctx, source)
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(ctx context.Context, msg types.MessageRef) error {
+	return s.append(record{Delete: &msg}, func() { s.memory.Delete(ctx, msg) })
+}
+
+// append writes r to the file and then calls apply to make the same change
+// in memory, so a failed write changes nothing.
+func (s *FileStore) append(r record, apply func()) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// Close closes the file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
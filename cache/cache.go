@@ -0,0 +1,229 @@
+// Package cache provides the size-bounded, expiring cache behind the
+// platform adapters' user lookups and the bot's state.
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults used when a cache is created with a zero size or TTL.
+const (
+	DefaultSize = 10000
+	DefaultTTL  = 10 * time.Minute
+)
+
+// ErrLoaderPanicked is returned by GetOrLoad to the callers waiting on a
+// loader that panicked.
+var ErrLoaderPanicked = errors.New("cache: loader panicked")
+
+// Stats reports the activity of a cache.
+type Stats struct {
+	Hits      uint64 // Lookups answered from the cache
+	Misses    uint64 // Lookups that were not cached or had expired
+	Loads     uint64 // Misses resolved by a loader, such as a REST call
+	Evictions uint64 // Entries dropped to stay within the size bound
+	Size      int    // Entries currently cached
+}
+
+// Add returns the sum of s and other.
+func (s Stats) Add(other Stats) Stats {
+	return Stats{
+		Hits:      s.Hits + other.Hits,
+		Misses:    s.Misses + other.Misses,
+		Loads:     s.Loads + other.Loads,
+		Evictions: s.Evictions + other.Evictions,
+		Size:      s.Size + other.Size,
+	}
+}
+
+// Cache maps keys to values for up to a TTL. When it is full, the least
+// recently used entry is evicted. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	items   map[K]*list.Element
+	order   *list.List // Front is most recently used
+	loading map[K]*load[V]
+	stats   Stats
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// load is a loader call in progress, shared by concurrent lookups of the
+// same key.
+type load[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+
+	// superseded is set when the key is set or deleted during the load,
+	// whose result is then older than the cache and not stored.
+	superseded bool
+}
+
+// New creates a cache holding up to size entries for ttl each. A zero size
+// or ttl selects DefaultSize or DefaultTTL, and a negative ttl keeps entries
+// until they are evicted.
+func New[K comparable, V any](size int, ttl time.Duration) *Cache[K, V] {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache[K, V]{
+		size:    size,
+		ttl:     ttl,
+		items:   make(map[K]*list.Element),
+		order:   list.New(),
+		loading: make(map[K]*load[V]),
+	}
+}
+
+// Get returns the cached value for key.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key)
+}
+
+func (c *Cache[K, V]) get(key K) (V, bool) {
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		if e.expires.IsZero() || time.Now().Before(e.expires) {
+			c.order.MoveToFront(elem)
+			c.stats.Hits++
+			return e.value, true
+		}
+		c.remove(elem)
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Set caches value for key, replacing any previous value and restarting
+// its TTL.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.supersede(key)
+	c.set(key, value)
+}
+
+func (c *Cache[K, V]) set(key K, value V) {
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete removes key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.supersede(key)
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// supersede keeps a load of key in progress from storing its result.
+func (c *Cache[K, V]) supersede(key K) {
+	if call, ok := c.loading[key]; ok {
+		call.superseded = true
+	}
+}
+
+// DeleteFunc removes every entry for which del returns true.
+func (c *Cache[K, V]) DeleteFunc(del func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if e := elem.Value.(*entry[K, V]); del(e.key, e.value) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (c *Cache[K, V]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
+
+// GetOrLoad returns the cached value for key, or calls load and caches its
+// result. Concurrent lookups of the same key share a single load call.
+// Errors are returned to every waiting caller and are not cached, and
+// neither is a result loaded while the key was set or deleted, since it may
+// be older than that change. If load panics, the panic continues in the
+// caller that ran it and the others get ErrLoaderPanicked.
+func (c *Cache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, error) {
+	c.mu.Lock()
+	if value, ok := c.get(key); ok {
+		c.mu.Unlock()
+		return value, nil
+	}
+	if call, ok := c.loading[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	// err is replaced by the loader's result unless it panics.
+	call := &load[V]{done: make(chan struct{}), err: ErrLoaderPanicked}
+	c.loading[key] = call
+	c.stats.Loads++
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.loading, key)
+		if call.err == nil && !call.superseded {
+			c.set(key, call.value)
+		}
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = loader()
+	return call.value, call.err
+}
+
+// Len returns the number of cached entries, including expired entries that
+// have not been looked up since.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the cache's counters.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.order.Len()
+	return stats
+}
@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c := New[string, int](2, -1)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // b is now the least recently used
+	c.Set("c", 3)
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"a", true},
+		{"b", false},
+		{"c", true},
+	}
+	for _, tt := range tests {
+		if _, ok := c.Get(tt.key); ok != tt.want {
+			t.Errorf("Get(%q) found = %v, want %v", tt.key, ok, tt.want)
+		}
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len = %d, want 2", n)
+	}
+
+	// Replacing a value does not evict anything.
+	c.Set("a", 10)
+	if v, _ := c.Get("a"); v != 10 || c.Len() != 2 {
+		t.Errorf("Get(a) = %d with %d entries, want 10 with 2", v, c.Len())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want bool
+	}{
+		{"expired", time.Millisecond, false},
+		{"fresh", time.Hour, true},
+		{"default", 0, true},
+		{"kept until evicted", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[string, int](0, tt.ttl)
+			c.Set("a", 1)
+			time.Sleep(5 * time.Millisecond)
+			if _, ok := c.Get("a"); ok != tt.want {
+				t.Errorf("Get found = %v, want %v", ok, tt.want)
+			}
+			// Expired entries are dropped once looked up.
+			if want := map[bool]int{true: 1, false: 0}[tt.want]; c.Len() != want {
+				t.Errorf("Len = %d, want %d", c.Len(), want)
+			}
+		})
+	}
+}
+
+func TestGetOrLoadShared(t *testing.T) {
+	c := New[string, int](0, 0)
+	gate := make(chan struct{})
+	var calls atomic.Int32
+	loader := func() (int, error) {
+		calls.Add(1)
+		<-gate
+		return 42, nil
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make(chan int, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad("a", loader)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- v
+		}()
+	}
+	waitFor(t, "every caller to miss", func() bool { return c.Stats().Misses == callers })
+	close(gate)
+	wg.Wait()
+	close(results)
+
+	for v := range results {
+		if v != 42 {
+			t.Errorf("GetOrLoad = %d, want 42", v)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader ran %d times, want once", n)
+	}
+	if v, ok := c.Get("a"); !ok || v != 42 {
+		t.Errorf("Get after the load = %d, %v; want the loaded value", v, ok)
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	c := New[string, int](0, 0)
+	failure := errors.New("not found")
+
+	if _, err := c.GetOrLoad("a", func() (int, error) { return 0, failure }); !errors.Is(err, failure) {
+		t.Errorf("GetOrLoad err = %v, want %v", err, failure)
+	}
+	// Errors are not cached.
+	if v, err := c.GetOrLoad("a", func() (int, error) { return 1, nil }); err != nil || v != 1 {
+		t.Errorf("GetOrLoad after a failure = %d, %v; want 1", v, err)
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c := New[string, int](0, 0)
+	started, gate := make(chan struct{}), make(chan struct{})
+
+	panicked := make(chan any)
+	go func() {
+		defer func() { panicked <- recover() }()
+		c.GetOrLoad("a", func() (int, error) {
+			close(started)
+			<-gate
+			panic("boom")
+		})
+	}()
+	<-started
+
+	waiter := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad("a", func() (int, error) { return 1, nil })
+		waiter <- err
+	}()
+	waitFor(t, "the second caller to wait", func() bool { return c.Stats().Misses == 2 })
+	close(gate)
+
+	if r := <-panicked; r != "boom" {
+		t.Errorf("loader's caller recovered %v, want the panic", r)
+	}
+	if err := <-waiter; !errors.Is(err, ErrLoaderPanicked) {
+		t.Errorf("waiting caller got %v, want ErrLoaderPanicked", err)
+	}
+	// The key is not stuck loading.
+	if v, err := c.GetOrLoad("a", func() (int, error) { return 2, nil }); err != nil || v != 2 {
+		t.Errorf("GetOrLoad after a panic = %d, %v; want 2", v, err)
+	}
+}
+
+func TestGetOrLoadSuperseded(t *testing.T) {
+	tests := []struct {
+		name   string
+		during func(c *Cache[string, int])
+		want   int
+		found  bool
+	}{
+		{"set", func(c *Cache[string, int]) { c.Set("a", 2) }, 2, true},
+		{"deleted", func(c *Cache[string, int]) { c.Delete("a") }, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New[string, int](0, 0)
+			v, err := c.GetOrLoad("a", func() (int, error) {
+				// The gateway reports a change while the REST call runs.
+				tt.during(c)
+				return 1, nil
+			})
+			if err != nil || v != 1 {
+				t.Fatalf("GetOrLoad = %d, %v; want the loaded 1", v, err)
+			}
+			if v, ok := c.Get("a"); v != tt.want || ok != tt.found {
+				t.Errorf("Get = %d, %v; want %d, %v", v, ok, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := New[string, int](1, 0)
+	c.Set("a", 1)
+	c.Get("a")                                              // hit
+	c.Get("b")                                              // miss
+	c.GetOrLoad("b", func() (int, error) { return 2, nil }) // miss, load, evicts a
+	c.GetOrLoad("b", func() (int, error) { return 3, nil }) // hit
+
+	want := Stats{Hits: 2, Misses: 2, Loads: 1, Evictions: 1, Size: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if sum := want.Add(want); sum != (Stats{Hits: 4, Misses: 4, Loads: 2, Evictions: 2, Size: 2}) {
+		t.Errorf("Add = %+v", sum)
+	}
+}
@@ -0,0 +1,116 @@
+// Command whiskercat runs the example WhiskerCat bot on Discord, Revolt and
+// Telegram.
+//
+// Credentials are read from the DISCORD_CLIENT_ID, DISCORD_CLIENT_SECRET,
+// DISCORD_TOKEN, REVOLT_TOKEN and TELEGRAM_TOKEN environment variables. A
+// platform whose token is unset is left unconfigured. Account links are
+// saved to the file named by LINKS_FILE, or kept in memory if it is unset.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/commands"
+	"github.com/luvixsocial/whiskercat/link"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+func main() {
+	config := &types.AuthConfig{}
+	if token := os.Getenv("DISCORD_TOKEN"); token != "" {
+		config.Discord = &types.DiscordConfig{
+			ClientID:     os.Getenv("DISCORD_CLIENT_ID"),
+			ClientSecret: os.Getenv("DISCORD_CLIENT_SECRET"),
+			Token:        token,
+		}
+	}
+	if token := os.Getenv("REVOLT_TOKEN"); token != "" {
+		config.Revolt = &types.RevoltConfig{Token: token}
+	}
+	if token := os.Getenv("TELEGRAM_TOKEN"); token != "" {
+		config.Telegram = &types.TelegramConfig{Token: token}
+	}
+
+	var opts []whiskercat.Option
+	if path := os.Getenv("LINKS_FILE"); path != "" {
+		store, err := link.NewFileStore(path)
+		if err != nil {
+			log.Fatalf("Error loading account links: %v", err)
+		}
+		opts = append(opts, whiskercat.WithLinkStore(store))
+	}
+	whiskercat.Config(config, opts...)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var stdout bool
+	registry := commands.New(&stdout)
+
+	whiskercat.Use(whiskercat.Recover(), whiskercat.IgnoreBots())
+
+	whiskercat.OnMessageCreate(func(evt types.Event, _ types.MessageCallback) {
+		commands.Handle(registry, evt, &stdout)
+	})
+
+	whiskercat.OnInteractionCreate(func(evt types.Event, _ types.InteractionCallback) {
+		commands.Handle(registry, evt, &stdout)
+	})
+
+	// Developer mode prints every event and posts an embed for it.
+	whiskercat.OnEvent(func(evt types.Event) {
+		if !stdout {
+			return
+		}
+		log.Printf("Received event: %v", evt)
+
+		if evt.Type != types.MessageCreate && evt.Type != types.InteractionCreate {
+			emitEventEmbed(evt)
+		}
+	})
+
+	if err := whiskercat.Start(ctx); err != nil {
+		log.Fatalf("Error starting bot: %v", err)
+	}
+
+	readyCtx, readyCancel := context.WithTimeout(ctx, 30*time.Second)
+	defer readyCancel()
+	if err := whiskercat.WaitReady(readyCtx); err != nil {
+		log.Fatalf("Bot did not become ready: %v", err)
+	}
+
+	whiskercat.SetStatus(types.ActivityTypeGame, "Luvix Social", types.Online, nil)
+
+	if err := registry.Publish(ctx, whiskercat.Default()); err != nil {
+		log.Printf("Error registering commands: %v", err)
+	}
+
+	<-ctx.Done()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+	if err := whiskercat.Stop(stopCtx); err != nil {
+		log.Printf("Error stopping bot: %v", err)
+	}
+}
+
+func emitEventEmbed(evt types.Event) {
+	if evt.ChannelID == "" {
+		return
+	}
+	_, err := whiskercat.SendMessage(evt.Platform, evt.ChannelID, "", &types.Embed{
+		Title:       "Event Received",
+		Description: fmt.Sprintf("%+v", evt),
+		Color:       0x00FF00,
+	})
+	if err != nil {
+		log.Printf("Error sending embed: %v", err)
+	}
+}
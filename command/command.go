@@ -0,0 +1,344 @@
+// Package command declares bot commands once and exposes them on every
+// platform.
+//
+// A Registry publishes its commands as Discord application commands and
+// Telegram bot commands, and also recognizes them in messages starting with
+// its prefix, such as "!ping", which is how Revolt, Matrix and IRC users run
+// them. Either way the handler receives the same Context.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// DefaultPrefix starts prefix commands when New is given an empty prefix.
+const DefaultPrefix = "!"
+
+// OptionType is the type of value an option takes.
+type OptionType int
+
+const (
+	String  OptionType = iota // Text; the last option of a prefix command takes the rest of the message
+	Integer                   // Whole number
+	Number                    // Decimal number
+	Boolean                   // true or false
+	User                      // User, as an ID or mention
+	Channel                   // Channel, as an ID or mention
+	Role                      // Role, as an ID or mention
+)
+
+// discordTypes maps option types to Discord's.
+var discordTypes = map[OptionType]discordgo.ApplicationCommandOptionType{
+	String:  discordgo.ApplicationCommandOptionString,
+	Integer: discordgo.ApplicationCommandOptionInteger,
+	Number:  discordgo.ApplicationCommandOptionNumber,
+	Boolean: discordgo.ApplicationCommandOptionBoolean,
+	User:    discordgo.ApplicationCommandOptionUser,
+	Channel: discordgo.ApplicationCommandOptionChannel,
+	Role:    discordgo.ApplicationCommandOptionRole,
+}
+
+// Option is an argument of a command.
+type Option struct {
+	Name        string
+	Description string
+	Type        OptionType
+	Required    bool // Required options must come before optional ones
+}
+
+// Command is a command and its handler.
+type Command struct {
+	Name        string // Lower case, up to 32 letters, digits or '_'
+	Description string // Up to 100 characters
+	Options     []Option
+	Handler     func(ctx *Context)
+}
+
+// Discord's rule for option names also allows '-' in command names, but
+// Telegram's does not, so command names follow the stricter one.
+var (
+	commandPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+	namePattern    = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+)
+
+func (c *Command) validate() error {
+	if !commandPattern.MatchString(c.Name) {
+		return fmt.Errorf("invalid command name %q", c.Name)
+	}
+	if c.Description == "" || len([]rune(c.Description)) > 100 {
+		return fmt.Errorf("command %s: description must be 1 to 100 characters", c.Name)
+	}
+	if c.Handler == nil {
+		return fmt.Errorf("command %s has no handler", c.Name)
+	}
+	seen := make(map[string]bool)
+	for i, opt := range c.Options {
+		switch {
+		case !namePattern.MatchString(opt.Name):
+			return fmt.Errorf("command %s: invalid option name %q", c.Name, opt.Name)
+		case seen[opt.Name]:
+			return fmt.Errorf("command %s: duplicate option %s", c.Name, opt.Name)
+		case opt.Description == "":
+			return fmt.Errorf("command %s: option %s has no description", c.Name, opt.Name)
+		case opt.Required && i > 0 && !c.Options[i-1].Required:
+			return fmt.Errorf("command %s: required option %s follows an optional one", c.Name, opt.Name)
+		}
+		if _, ok := discordTypes[opt.Type]; !ok {
+			return fmt.Errorf("command %s: option %s has unknown type %d", c.Name, opt.Name, opt.Type)
+		}
+		seen[opt.Name] = true
+	}
+	return nil
+}
+
+// Usage returns how to run the command with prefix, such as
+// "!ban <user> [reason]".
+func (c *Command) Usage(prefix string) string {
+	var b strings.Builder
+	b.WriteString(prefix + c.Name)
+	for _, opt := range c.Options {
+		if opt.Required {
+			fmt.Fprintf(&b, " <%s>", opt.Name)
+		} else {
+			fmt.Fprintf(&b, " [%s]", opt.Name)
+		}
+	}
+	return b.String()
+}
+
+// Context is passed to a command's handler. Its InteractionCallback holds
+// the command name, the invoker and the option values, and is also the Data
+// of Event.
+type Context struct {
+	types.InteractionCallback
+
+	// Event is the event that ran the command. Prefix commands arrive as
+	// InteractionCreate events too, whose Context is the raw message.
+	Event types.Event
+
+	// Message is the message that ran a prefix command, or nil for
+	// commands run natively.
+	Message *types.MessageCallback
+}
+
+// String returns the value of an option, or "" if it was not given.
+func (c *Context) String(name string) string {
+	return c.Fields[name]
+}
+
+// Int returns the value of an Integer option, or 0 if it was not given.
+func (c *Context) Int(name string) int64 {
+	n, _ := strconv.ParseInt(c.Fields[name], 10, 64)
+	return n
+}
+
+// Float returns the value of a Number option, or 0 if it was not given.
+func (c *Context) Float(name string) float64 {
+	f, _ := strconv.ParseFloat(c.Fields[name], 64)
+	return f
+}
+
+// Bool returns the value of a Boolean option, or false if it was not given.
+func (c *Context) Bool(name string) bool {
+	b, _ := strconv.ParseBool(c.Fields[name])
+	return b
+}
+
+// Has reports whether an option was given.
+func (c *Context) Has(name string) bool {
+	_, ok := c.Fields[name]
+	return ok
+}
+
+// Respond replies to the command, like whiskercat.Respond.
+func (c *Context) Respond(content string, embed *types.Embed) (*types.SentMessage, error) {
+	return whiskercat.Respond(c.Event, content, embed, nil)
+}
+
+// Registry holds commands. It is safe for concurrent use.
+type Registry struct {
+	prefix string
+
+	mu       sync.RWMutex
+	commands []*Command
+	byName   map[string]*Command
+}
+
+// New creates an empty registry whose prefix commands start with prefix. An
+// empty prefix selects DefaultPrefix.
+func New(prefix string) *Registry {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Registry{prefix: prefix, byName: make(map[string]*Command)}
+}
+
+// Prefix returns the prefix of prefix commands.
+func (r *Registry) Prefix() string {
+	return r.prefix
+}
+
+// Register adds commands. Commands are declared in code, so it panics if
+// one is invalid or its name is already taken.
+func (r *Registry) Register(cmds ...Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cmd := range cmds {
+		if err := cmd.validate(); err != nil {
+			panic("command: " + err.Error())
+		}
+		if _, ok := r.byName[cmd.Name]; ok {
+			panic("command: " + cmd.Name + " is already registered")
+		}
+		r.commands = append(r.commands, &cmd)
+		r.byName[cmd.Name] = &cmd
+	}
+}
+
+// Lookup returns the named command.
+func (r *Registry) Lookup(name string) (Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if cmd, ok := r.byName[strings.ToLower(name)]; ok {
+		return *cmd, true
+	}
+	return Command{}, false
+}
+
+// Commands returns the commands in the order they were registered.
+func (r *Registry) Commands() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmds := make([]Command, len(r.commands))
+	for i, cmd := range r.commands {
+		cmds[i] = *cmd
+	}
+	return cmds
+}
+
+// Definitions returns the commands as Discord application commands, which
+// Telegram's SetCommands also accepts.
+func (r *Registry) Definitions() []*discordgo.ApplicationCommand {
+	var defs []*discordgo.ApplicationCommand
+	for _, cmd := range r.Commands() {
+		var opts []*discordgo.ApplicationCommandOption
+		for _, opt := range cmd.Options {
+			opts = append(opts, whiskercat.NewOption(opt.Name, opt.Description, discordTypes[opt.Type], opt.Required))
+		}
+		defs = append(defs, whiskercat.NewCommand(cmd.Name, cmd.Description, opts...))
+	}
+	return defs
+}
+
+// Publish registers the commands with Discord and Telegram, where the bot
+// is configured. Call it once the bot is ready.
+func (r *Registry) Publish(ctx context.Context, bot *whiskercat.Bot) error {
+	var errs []error
+	if s := bot.Discord(); s != nil {
+		if s.State.Application == nil {
+			errs = append(errs, errors.New("discord commands: bot is not ready"))
+		} else if _, err := whiskercat.EnsureSlashCommands(s, s.State.Application.ID, "", r.Definitions()); err != nil {
+			errs = append(errs, fmt.Errorf("discord commands: %w", err))
+		}
+	}
+	if tg := bot.Telegram(); tg != nil {
+		if err := tg.SetCommands(ctx, r.Definitions()); err != nil {
+			errs = append(errs, fmt.Errorf("telegram commands: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Attach runs the commands for the bot's interactions and prefixed
+// messages until remove is called.
+func (r *Registry) Attach(bot *whiskercat.Bot) (remove func()) {
+	removeInteractions := bot.OnInteractionCreate(func(evt types.Event, _ types.InteractionCallback) { r.Handle(evt) })
+	removeMessages := bot.OnMessageCreate(func(evt types.Event, _ types.MessageCallback) { r.Handle(evt) })
+	return func() {
+		removeInteractions()
+		removeMessages()
+	}
+}
+
+// Handle runs the command an InteractionCreate or MessageCreate event
+// invokes, and reports whether it named one. Messages from bots and button
+// presses are ignored, even when a button's data equals a command name. A
+// prefix or Telegram command with missing or malformed options is answered
+// with its usage instead of being run.
+func (r *Registry) Handle(evt types.Event) bool {
+	switch data := evt.Data.(type) {
+	case types.InteractionCallback:
+		if data.Button {
+			return false
+		}
+		cmd, ok := r.Lookup(data.Name)
+		if !ok {
+			return false
+		}
+		// Commands typed as text, such as Telegram's, arrive with their
+		// arguments unparsed.
+		if data.Fields == nil {
+			if data.Fields, ok = parseArgs(cmd.Options, data.Args); !ok {
+				usage(evt, cmd, "/")
+				return true
+			}
+			evt.Data = data
+		}
+		cmd.Handler(&Context{InteractionCallback: data, Event: evt})
+		return true
+
+	case types.MessageCallback:
+		if evt.Bot || evt.Self {
+			return false
+		}
+		name, args, ok := r.split(data.Content)
+		if !ok {
+			return false
+		}
+		cmd, ok := r.Lookup(name)
+		if !ok {
+			return false
+		}
+
+		callback := types.InteractionCallback{Name: cmd.Name, Author: data.Author, DM: data.DM}
+		callback.Fields, ok = parseArgs(cmd.Options, args)
+		if !ok {
+			usage(evt, cmd, r.prefix)
+			return true
+		}
+
+		invoked := evt
+		invoked.Name = string(types.InteractionCreate)
+		invoked.Type = types.InteractionCreate
+		invoked.Data = callback
+		cmd.Handler(&Context{InteractionCallback: callback, Event: invoked, Message: &data})
+		return true
+	}
+	return false
+}
+
+// usage answers a command with missing or malformed options with its usage.
+func usage(evt types.Event, cmd Command, prefix string) {
+	whiskercat.Respond(evt, "Usage: `"+cmd.Usage(prefix)+"`", nil, nil)
+}
+
+// split separates a prefixed message into the command name and its
+// arguments.
+func (r *Registry) split(content string) (name, args string, ok bool) {
+	rest, ok := strings.CutPrefix(content, r.prefix)
+	if !ok {
+		return "", "", false
+	}
+	name, args = cut(rest)
+	return name, args, name != ""
+}
@@ -0,0 +1,194 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/luvixsocial/whiskercat/command"
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/luvixsocial/whiskercat/whiskercattest"
+)
+
+// warn is a command whose handler records the options it ran with.
+func warn(got *map[string]string) command.Command {
+	return command.Command{
+		Name:        "warn",
+		Description: "Warn a member",
+		Options: []command.Option{
+			{Name: "user", Description: "Member to warn", Type: command.User, Required: true},
+			{Name: "points", Description: "Points to add", Type: command.Integer, Required: true},
+			{Name: "reason", Description: "Why", Type: command.String},
+		},
+		Handler: func(ctx *command.Context) {
+			*got = ctx.Fields
+			ctx.Respond("warned", nil)
+		},
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name  string
+		event func(h *whiskercattest.Harness)
+		want  map[string]string // nil if the command must not run
+		reply string
+	}{
+		{
+			name: "prefix",
+			event: func(h *whiskercattest.Harness) {
+				h.InjectMessage("general", whiskercattest.User("alice"), "!warn <@123> 2 spamming links")
+			},
+			want:  map[string]string{"user": "123", "points": "2", "reason": "spamming links"},
+			reply: "warned",
+		},
+		{
+			name: "prefix without optional option",
+			event: func(h *whiskercattest.Harness) {
+				h.InjectMessage("general", whiskercattest.User("alice"), "!WARN 123 2")
+			},
+			want:  map[string]string{"user": "123", "points": "2"},
+			reply: "warned",
+		},
+		{
+			name: "prefix missing required option",
+			event: func(h *whiskercattest.Harness) {
+				h.InjectMessage("general", whiskercattest.User("alice"), "!warn 123")
+			},
+			reply: "Usage: `!warn <user> <points> [reason]`",
+		},
+		{
+			name: "prefix with malformed integer",
+			event: func(h *whiskercattest.Harness) {
+				h.InjectMessage("general", whiskercattest.User("alice"), "!warn 123 many")
+			},
+			reply: "Usage: `!warn <user> <points> [reason]`",
+		},
+		{
+			name: "native interaction",
+			event: func(h *whiskercattest.Harness) {
+				h.InjectInteraction("general", whiskercattest.User("alice"), "warn", map[string]string{"user": "123", "points": "1"})
+			},
+			want:  map[string]string{"user": "123", "points": "1"},
+			reply: "warned",
+		},
+		{
+			name: "text interaction",
+			event: func(h *whiskercattest.Harness) {
+				h.Emit(types.Event{
+					Type:      types.InteractionCreate,
+					ChannelID: "general",
+					Data:      types.InteractionCallback{Name: "warn", Args: "123 3 flooding"},
+				})
+			},
+			want:  map[string]string{"user": "123", "points": "3", "reason": "flooding"},
+			reply: "warned",
+		},
+		{
+			name: "text interaction missing required option",
+			event: func(h *whiskercattest.Harness) {
+				h.Emit(types.Event{
+					Type:      types.InteractionCreate,
+					ChannelID: "general",
+					Data:      types.InteractionCallback{Name: "warn", Args: "123"},
+				})
+			},
+			reply: "Usage: `/warn <user> <points> [reason]`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := whiskercattest.New(t)
+			var got map[string]string
+			registry := command.New("")
+			registry.Register(warn(&got))
+			registry.Attach(h.Bot)
+
+			tt.event(h)
+
+			if tt.want == nil && got != nil {
+				t.Errorf("command ran with %v", got)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("option %s = %q, want %q", name, got[name], value)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("options = %v, want %v", got, tt.want)
+			}
+			h.AssertSent(tt.reply)
+		})
+	}
+}
+
+func TestHandleIgnoresBots(t *testing.T) {
+	h := whiskercattest.New(t)
+	var got map[string]string
+	registry := command.New("")
+	registry.Register(warn(&got))
+	registry.Attach(h.Bot)
+
+	h.Emit(types.Event{
+		Type:      types.MessageCreate,
+		ChannelID: "general",
+		Bot:       true,
+		Data:      types.MessageCallback{ChannelID: "general", Content: "!warn 123 1"},
+	})
+	h.InjectMessage("general", whiskercattest.User("alice"), "warn 123 1")
+	h.AssertNoActions()
+}
+
+func TestRegisterRejects(t *testing.T) {
+	handler := func(*command.Context) {}
+	tests := []struct {
+		name string
+		cmd  command.Command
+	}{
+		{"hyphenated name", command.Command{Name: "set-name", Description: "d", Handler: handler}},
+		{"upper case name", command.Command{Name: "Ping", Description: "d", Handler: handler}},
+		{"no description", command.Command{Name: "ping", Handler: handler}},
+		{"no handler", command.Command{Name: "ping", Description: "d"}},
+		{"required after optional", command.Command{Name: "ping", Description: "d", Handler: handler, Options: []command.Option{
+			{Name: "a", Description: "a"},
+			{Name: "b", Description: "b", Required: true},
+		}}},
+		{"duplicate option", command.Command{Name: "ping", Description: "d", Handler: handler, Options: []command.Option{
+			{Name: "a", Description: "a"},
+			{Name: "a", Description: "a"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register did not panic")
+				}
+			}()
+			command.New("").Register(tt.cmd)
+		})
+	}
+
+	// Only command names must do without '-'.
+	command.New("").Register(command.Command{Name: "ping", Description: "d", Handler: handler, Options: []command.Option{
+		{Name: "dry-run", Description: "d", Type: command.Boolean},
+	}})
+}
+
+func TestHandleIgnoresButtons(t *testing.T) {
+	h := whiskercattest.New(t)
+	var got map[string]string
+	registry := command.New("")
+	registry.Register(warn(&got))
+	registry.Attach(h.Bot)
+
+	// A Telegram button whose callback data is a command name.
+	h.Emit(types.Event{
+		Type:      types.InteractionCreate,
+		ChannelID: "general",
+		Data:      types.InteractionCallback{Name: "warn", Button: true},
+	})
+	if got != nil {
+		t.Errorf("button press ran the command with %v", got)
+	}
+	h.AssertNoActions()
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseArgs assigns the arguments of a prefix command to its options in
+// order, like Telegram commands; the last option receives the rest of the
+// text. It reports false if a required option is missing or a value does
+// not match its option's type.
+func parseArgs(options []Option, args string) (map[string]string, bool) {
+	fields := make(map[string]string)
+	for i, opt := range options {
+		if args == "" {
+			if opt.Required {
+				return nil, false
+			}
+			break
+		}
+
+		var value string
+		if i == len(options)-1 {
+			value = args
+		} else {
+			value, args = cut(args)
+		}
+		value, ok := convert(opt.Type, value)
+		if !ok {
+			return nil, false
+		}
+		fields[opt.Name] = value
+	}
+	return fields, true
+}
+
+// convert checks a value against an option type and normalizes it to the
+// form Discord uses in interactions.
+func convert(typ OptionType, value string) (string, bool) {
+	switch typ {
+	case Integer:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return value, err == nil
+	case Number:
+		_, err := strconv.ParseFloat(value, 64)
+		return value, err == nil
+	case Boolean:
+		b, err := strconv.ParseBool(value)
+		return strconv.FormatBool(b), err == nil
+	case User, Channel, Role:
+		id := mentionID(value)
+		return id, id != "" && !strings.ContainsFunc(id, unicode.IsSpace)
+	}
+	return value, true
+}
+
+// mentionID returns the ID in a mention such as <@123>, <@!123>, <@&123>
+// or <#123>, or the value itself if it is not a mention.
+func mentionID(value string) string {
+	if inner, ok := strings.CutPrefix(value, "<"); ok {
+		if inner, ok = strings.CutSuffix(inner, ">"); ok {
+			return strings.TrimLeft(inner, "@!&#%")
+		}
+	}
+	return value
+}
+
+// cut splits s at its first run of white space.
+func cut(s string) (before, after string) {
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
@@ -1,15 +1,16 @@
 package commands
 
 import (
-	"github.com/luvixsocial/whiskercat/types"
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/command"
 )
 
-func EnableDev(evt types.Event, stdout *bool) {
+func EnableDev(ctx *command.Context, stdout *bool) {
 	*stdout = true
-	Respond(evt, "Enabled developer mode.", nil, nil)
+	whiskercat.Respond(ctx.Event, "Enabled developer mode.", nil, nil)
 }
 
-func DisableDev(evt types.Event, stdout *bool) {
+func DisableDev(ctx *command.Context, stdout *bool) {
 	*stdout = false
-	Respond(evt, "Disabled developer mode.", nil, nil)
+	whiskercat.Respond(ctx.Event, "Disabled developer mode.", nil, nil)
 }
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/command"
+	"github.com/luvixsocial/whiskercat/link"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Link issues a link code when run without one, and confirms a code issued
+// on another platform when given one. Codes are only issued where nobody
+// else can read them: in direct messages and Discord slash commands, whose
+// responses are ephemeral.
+func Link(ctx *command.Context) {
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := ctx.Author.Ref(ctx.Event.Platform)
+	code := ctx.String("code")
+
+	if code == "" {
+		if !private(ctx) {
+			respondPrivately(ctx, "Run the link command in a direct message to get a link code.")
+			return
+		}
+		code, err := whiskercat.Links().Issue(user)
+		if err != nil {
+			log.Printf("Error issuing link code: %v\n", err)
+			return
+		}
+		respondPrivately(ctx, fmt.Sprintf("Your link code is `%s`. Send `%slink %s` to the bot on the other platform within %d minutes.", code, Prefix, code, int(link.DefaultCodeTTL.Minutes())))
+		return
+	}
+
+	linked, err := whiskercat.Links().Confirm(c, code, user)
+	switch {
+	case errors.Is(err, link.ErrInvalidCode):
+		respondPrivately(ctx, "That link code is invalid or has expired.")
+	case errors.Is(err, link.ErrSamePlatform):
+		respondPrivately(ctx, "Confirm the link code on the other platform.")
+	case err != nil:
+		log.Printf("Error confirming link code: %v\n", err)
+	default:
+		name := linked.String()
+		if u, err := whiskercat.User(c, linked.Platform, linked.ID); err == nil {
+			name = u.Username
+		}
+		respondPrivately(ctx, fmt.Sprintf("Linked to %s on %s.", name, linked.Platform))
+	}
+}
+
+// Unlink removes the invoker's account links.
+func Unlink(ctx *command.Context) {
+	if err := whiskercat.Links().Unlink(context.Background(), ctx.Author.Ref(ctx.Event.Platform)); err != nil {
+		log.Printf("Error unlinking account: %v\n", err)
+		return
+	}
+	respondPrivately(ctx, "Your accounts are no longer linked.")
+}
+
+// private reports whether a response is only seen by the invoker: it is
+// in a direct message, or answers a Discord interaction and is ephemeral.
+func private(ctx *command.Context) bool {
+	return ctx.DM || ctx.Data != nil
+}
+
+func respondPrivately(ctx *command.Context, content string) {
+	if ctx.Event.Source == nil {
+		return
+	}
+	if _, err := ctx.Event.Source.Respond(ctx.Event, types.MessageSend{Content: content, Ephemeral: true}, nil); err != nil {
+		log.Printf("Error responding: %v\n", err)
+	}
+}
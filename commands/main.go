@@ -2,55 +2,70 @@ package commands
 
 import (
 	"fmt"
-	"time"
 
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/command"
 	"github.com/luvixsocial/whiskercat/types"
-
-	"github.com/bwmarrin/discordgo"
-	"github.com/sentinelb51/revoltgo"
 )
 
-var commandMap = map[string]func(types.Event, *bool){
-	"ping":        Ping,
-	"test":        Test,
-	"test_embed":  TestEmbed,
-	"enable_dev":  EnableDev,
-	"disable_dev": DisableDev,
-}
-
-func Handle(evt types.Event, stdout *bool, name string) {
-	if handler, ok := commandMap[name]; ok {
-		handler(evt, stdout)
-	} else if *stdout {
-		Respond(evt, "", &Embed{
-			Title:       "types.Event Received",
-			Description: fmt.Sprintf("%+v", evt),
-			Color:       0x00FF00,
-		}, nil)
-	}
-}
+// Prefix starts the example bot's commands in messages.
+const Prefix = "!"
 
-func Definitions() []*discordgo.ApplicationCommand {
-	return []*discordgo.ApplicationCommand{
-		{
+// New returns the example bot's commands. The developer mode commands
+// switch stdout.
+func New(stdout *bool) *command.Registry {
+	r := command.New(Prefix)
+	r.Register(
+		command.Command{
 			Name:        "ping",
 			Description: "Check latency",
+			Handler:     Ping,
 		},
-		{
+		command.Command{
 			Name:        "test",
 			Description: "Test types.event reply",
+			Handler:     Test,
 		},
-		{
+		command.Command{
 			Name:        "test_embed",
 			Description: "Send a test embed",
+			Handler:     TestEmbed,
 		},
-		{
+		command.Command{
 			Name:        "enable_dev",
 			Description: "Enable developer mode",
+			Handler:     func(ctx *command.Context) { EnableDev(ctx, stdout) },
 		},
-		{
+		command.Command{
 			Name:        "disable_dev",
 			Description: "Disable developer mode",
+			Handler:     func(ctx *command.Context) { DisableDev(ctx, stdout) },
 		},
+		command.Command{
+			Name:        "link",
+			Description: "Link your accounts on different platforms",
+			Options: []command.Option{
+				{Name: "code", Description: "Code issued on the other platform", Type: command.String},
+			},
+			Handler: Link,
+		},
+		command.Command{
+			Name:        "unlink",
+			Description: "Unlink your accounts",
+			Handler:     Unlink,
+		},
+	)
+	return r
+}
+
+// Handle runs the command evt invokes. In developer mode, events that run
+// no command are echoed back.
+func Handle(r *command.Registry, evt types.Event, stdout *bool) {
+	if !r.Handle(evt) && *stdout {
+		whiskercat.Respond(evt, "", &types.Embed{
+			Title:       "types.Event Received",
+			Description: fmt.Sprintf("%+v", evt),
+			Color:       0x00FF00,
+		}, nil)
 	}
 }
@@ -4,25 +4,18 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/luvixsocial/whiskercat/types"
-
-	"github.com/bwmarrin/discordgo"
-	"github.com/sentinelb51/revoltgo"
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/command"
 )
 
-func Ping(evt types.Event, _ *bool) {
+func Ping(ctx *command.Context) {
 	start := time.Now()
-	msgRaw, err := Respond(evt, "Pinging...", nil, nil)
+	msg, err := whiskercat.Respond(ctx.Event, "Pinging...", nil, nil)
 	if err != nil {
 		fmt.Printf("Error sending ping: %v\n", err)
 		return
 	}
 	latency := time.Since(start).Milliseconds()
 	pong := fmt.Sprintf("Pong! %dms", latency)
-	switch msg := msgRaw.(type) {
-	case *discordgo.Message:
-		Respond(evt, pong, nil, &msg.ID)
-	case *revoltgo.Message:
-		Respond(evt, pong, nil, &msg.ID)
-	}
+	whiskercat.Respond(ctx.Event, pong, nil, &msg.ID)
 }
@@ -1,5 +1,10 @@
 package commands
 
-func Test(evt Event, _ *bool) {
-	Respond(evt, "Received test event!", nil, nil)
+import (
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/command"
+)
+
+func Test(ctx *command.Context) {
+	whiskercat.Respond(ctx.Event, "Received test event!", nil, nil)
 }
@@ -2,22 +2,25 @@ package commands
 
 import (
 	"fmt"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/command"
 	"github.com/luvixsocial/whiskercat/types"
 )
 
-func TestEmbed(evt types.Event, _ *bool) {
-	_, err := Respond(evt, "", &Embed{
+func TestEmbed(ctx *command.Context) {
+	_, err := whiskercat.Respond(ctx.Event, "", &types.Embed{
 		Title:       "Test Embed",
 		Description: "This is a test embed.",
-		URL:         ptr("https://purrquinox.com/"),
-		IconURL:     ptr("https://purrquinox.com/logo.png"),
-		Fields: ptr([]EmbedField{
+		URL:         whiskercat.Ptr("https://purrquinox.com/"),
+		IconURL:     whiskercat.Ptr("https://purrquinox.com/logo.png"),
+		Fields: whiskercat.Ptr([]types.EmbedField{
 			{
 				Name:  "Test Field",
 				Value: "This is a test field.",
 			},
 		}),
-		Footer: &EmbedFooter{
+		Footer: &types.EmbedFooter{
 			Text:     "This is a test footer.",
 			PhotoURL: "https://purrquinox.com/logo.png",
 		},
@@ -1,25 +1,88 @@
-package main
+package whiskercat
 
 import (
-	"github.com/bwmarrin/discordgo"
-	"github.com/luvixsocial/whiskercat/types"
-	"github.com/sentinelb51/revoltgo"
+	"fmt"
 	"log"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/link"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/state"
+	"github.com/luvixsocial/whiskercat/types"
+
+	// Built-in platform adapters register themselves on import.
+	_ "github.com/luvixsocial/whiskercat/platform/discord"
+	_ "github.com/luvixsocial/whiskercat/platform/irc"
+	_ "github.com/luvixsocial/whiskercat/platform/matrix"
+	_ "github.com/luvixsocial/whiskercat/platform/revolt"
+	_ "github.com/luvixsocial/whiskercat/platform/telegram"
 )
 
-// Config sets up and initializes both Discord and Revolt clients using the provided configuration.
+// New creates a bot with every registered platform adapter built from config.
+// Nothing connects until Start is called.
+func New(config types.AuthConfig, opts ...Option) (*Bot, error) {
+	platforms, err := platform.Open(&config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize platforms: %w", err)
+	}
+
+	list := make([]platform.Platform, 0, len(platforms))
+	for _, p := range platforms {
+		list = append(list, p)
+	}
+	return NewWithPlatforms(list, opts...), nil
+}
+
+// NewWithPlatforms creates a bot from already constructed platform adapters.
+func NewWithPlatforms(platforms []platform.Platform, opts ...Option) *Bot {
+	b := &Bot{
+		platforms:      make(map[string]platform.Platform, len(platforms)),
+		platformNames:  make(map[string]string, len(platforms)),
+		cooldowns:      make(map[string]time.Time),
+		ready:          make(chan struct{}),
+		supervisor:     newSupervisor(DefaultReconnectPolicy),
+		dispatchConfig: DefaultDispatchConfig,
+		stateConfig:    state.DefaultConfig,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.state = state.New(b.stateConfig)
+	if b.linkStore == nil {
+		b.linkStore = link.NewMemoryStore()
+	}
+	b.links = link.New(b.linkStore, 0)
+
+	if b.dispatchConfig.Workers > 0 {
+		b.dispatcher = newDispatcher(b.dispatchConfig, b.handle, func(types.Event) { b.inflight.Done() })
+	}
+
+	for _, p := range platforms {
+		if d, ok := p.(platform.Directory); ok && b.directoryCache != (platform.CacheConfig{}) {
+			d.ConfigureCache(b.directoryCache)
+		}
+		b.platforms[p.Name()] = p
+		b.platformNames[types.PlatformKey(p.Name())] = p.Name()
+		p.HandleEvents(func(evt types.Event) {
+			evt.Source = p
+			b.supervisor.observe(p, evt)
+			b.state.Apply(evt)
+			b.dispatch(evt)
+		})
+	}
+	return b
+}
+
+// Config sets up the default bot used by the package-level helpers.
 //
 // This should be called before any event handlers or client operations.
-// It panics if Discord client initialization fails.
-func Config(config *types.AuthConfig) {
-	var err error
-
-	// Initialize Discord client
-	Discord, err = discordgo.New("Bot " + config.Discord.Token)
+// It exits the program if a platform fails to initialize.
+func Config(config *types.AuthConfig, opts ...Option) {
+	b, err := New(*config, opts...)
 	if err != nil {
-		log.Fatalf("Failed to initialize Discord client: %v", err)
+		log.Fatal(err)
 	}
-
-	// Initialize Revolt client
-	Revolt = revoltgo.New(config.Revolt.Token)
+	defaultBot = b
 }
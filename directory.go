@@ -0,0 +1,72 @@
+package whiskercat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// directory returns the named platform's user directory.
+func (b *Bot) directory(name string) (platform.Directory, error) {
+	p, err := b.lookupPlatform(name)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := p.(platform.Directory)
+	if !ok {
+		return nil, fmt.Errorf("%s user lookups: %w", name, errors.ErrUnsupported)
+	}
+	return d, nil
+}
+
+// User looks up a user on the named platform. Users seen in gateway events
+// are answered from a cache; others are fetched from the platform's API.
+func (b *Bot) User(ctx context.Context, platform, userID string) (types.User, error) {
+	d, err := b.directory(platform)
+	if err != nil {
+		return types.User{}, err
+	}
+	return d.User(ctx, userID)
+}
+
+// Member looks up a user's membership of a server on the named platform,
+// from the cache when possible.
+func (b *Bot) Member(ctx context.Context, platform, serverID, userID string) (types.MemberCallback, error) {
+	d, err := b.directory(platform)
+	if err != nil {
+		return types.MemberCallback{}, err
+	}
+	return d.Member(ctx, serverID, userID)
+}
+
+// CacheStats reports the user and member cache activity of each platform
+// that has one, keyed by platform name.
+func (b *Bot) CacheStats() map[string]cache.Stats {
+	stats := make(map[string]cache.Stats)
+	for name, p := range b.platforms {
+		if d, ok := p.(platform.Directory); ok {
+			stats[name] = d.CacheStats()
+		}
+	}
+	return stats
+}
+
+// User looks up a user using the default bot.
+func User(ctx context.Context, platform, userID string) (types.User, error) {
+	if defaultBot == nil {
+		return types.User{}, errNotConfigured
+	}
+	return defaultBot.User(ctx, platform, userID)
+}
+
+// Member looks up a server member using the default bot.
+func Member(ctx context.Context, platform, serverID, userID string) (types.MemberCallback, error) {
+	if defaultBot == nil {
+		return types.MemberCallback{}, errNotConfigured
+	}
+	return defaultBot.Member(ctx, platform, serverID, userID)
+}
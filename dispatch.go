@@ -0,0 +1,194 @@
+package whiskercat
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// OverflowPolicy decides what happens to an event when its worker's queue
+// is full.
+type OverflowPolicy int
+
+const (
+	// Block makes the platform wait until the queue has room. No event is
+	// lost, but a slow handler slows down the gateway.
+	Block OverflowPolicy = iota
+
+	// DropOldest discards the oldest queued event to make room.
+	DropOldest
+
+	// DropNewest discards the incoming event.
+	DropNewest
+)
+
+// DispatchConfig configures how events are handed to handlers.
+//
+// Events are spread over Workers goroutines by channel (or by server for
+// events without a channel), so events of one channel are handled one at
+// a time and in the order they arrived, and an edit never overtakes the
+// message it edits. Events of different channels run concurrently.
+type DispatchConfig struct {
+	Workers   int            // Number of worker goroutines; 0 runs handlers inline on the platform's goroutine
+	QueueSize int            // Events buffered across all workers before Overflow applies
+	Overflow  OverflowPolicy // What to do when a worker's queue is full
+}
+
+// DefaultDispatchConfig is used unless WithDispatch is given.
+var DefaultDispatchConfig = DispatchConfig{
+	Workers:   8,
+	QueueSize: 1024,
+	Overflow:  Block,
+}
+
+// DispatchStats reports the state of the dispatcher.
+type DispatchStats struct {
+	Queued  int    // Events waiting for a worker
+	Dropped uint64 // Events discarded by the overflow policy
+}
+
+// dispatcher runs events on a fixed set of workers, each with its own
+// bounded queue.
+type dispatcher struct {
+	overflow OverflowPolicy
+	queues   []chan types.Event
+	handle   func(types.Event)
+	discard  func(types.Event)
+
+	dropped   atomic.Uint64
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newDispatcher(config DispatchConfig, handle, discard func(types.Event)) *dispatcher {
+	size := max(config.QueueSize/config.Workers, 1)
+	d := &dispatcher{
+		overflow: config.Overflow,
+		queues:   make([]chan types.Event, config.Workers),
+		handle:   handle,
+		discard:  discard,
+		done:     make(chan struct{}),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan types.Event, size)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+func (d *dispatcher) work(queue chan types.Event) {
+	for {
+		select {
+		case evt := <-queue:
+			d.handle(evt)
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// submit queues evt on the worker that owns its channel.
+func (d *dispatcher) submit(evt types.Event) {
+	queue := d.queues[d.shard(evt)]
+
+	switch d.overflow {
+	case DropNewest:
+		select {
+		case queue <- evt:
+		default:
+			d.drop(evt)
+			return
+		}
+
+	case DropOldest:
+	push:
+		for {
+			select {
+			case queue <- evt:
+				break push
+			default:
+			}
+			select {
+			case old := <-queue:
+				d.drop(old)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case queue <- evt:
+		case <-d.done:
+			d.drop(evt)
+			return
+		}
+	}
+
+	// An event queued after close would never be handled.
+	select {
+	case <-d.done:
+		d.drain(queue)
+	default:
+	}
+}
+
+func (d *dispatcher) drop(evt types.Event) {
+	d.dropped.Add(1)
+	d.discard(evt)
+}
+
+// drain discards the events left in a queue.
+func (d *dispatcher) drain(queue chan types.Event) {
+	for {
+		select {
+		case evt := <-queue:
+			d.discard(evt)
+		default:
+			return
+		}
+	}
+}
+
+// shard picks the worker for an event from its platform and channel.
+func (d *dispatcher) shard(evt types.Event) int {
+	key := evt.ChannelID
+	if key == "" {
+		key = evt.ServerID
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(evt.Platform))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(d.queues)))
+}
+
+func (d *dispatcher) stats() DispatchStats {
+	stats := DispatchStats{Dropped: d.dropped.Load()}
+	for _, queue := range d.queues {
+		stats.Queued += len(queue)
+	}
+	return stats
+}
+
+// close stops the workers. Events still queued are not handled but
+// discarded, without counting them as dropped.
+func (d *dispatcher) close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		for _, queue := range d.queues {
+			d.drain(queue)
+		}
+	})
+}
+
+// DispatchStats reports how many events are queued and how many the
+// overflow policy has dropped. It is zero when handlers run inline.
+func (b *Bot) DispatchStats() DispatchStats {
+	if b.dispatcher == nil {
+		return DispatchStats{}
+	}
+	return b.dispatcher.stats()
+}
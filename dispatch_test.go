@@ -0,0 +1,246 @@
+package whiskercat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// message returns a MessageCreate in channel, numbered n.
+func message(channel string, n int) types.Event {
+	return types.Event{Type: types.MessageCreate, Platform: "Fake", ChannelID: channel, Data: n}
+}
+
+// wait fails the test if ch is not closed or sent to within a second.
+func wait[T any](t *testing.T, ch <-chan T, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestDispatchOrderPerChannel(t *testing.T) {
+	const channels, perChannel = 8, 200
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string][]int)
+		wg   sync.WaitGroup
+	)
+	wg.Add(channels * perChannel)
+	d := newDispatcher(DispatchConfig{Workers: 4, QueueSize: 16}, func(evt types.Event) {
+		mu.Lock()
+		seen[evt.ChannelID] = append(seen[evt.ChannelID], evt.Data.(int))
+		mu.Unlock()
+		wg.Done()
+	}, func(types.Event) { t.Error("Block dropped an event") })
+	defer d.close()
+
+	// Channels are submitted from separate goroutines, as separate
+	// platforms would.
+	var submitters sync.WaitGroup
+	for c := range channels {
+		submitters.Add(1)
+		go func() {
+			defer submitters.Done()
+			for n := range perChannel {
+				d.submit(message(fmt.Sprint("channel-", c), n))
+			}
+		}()
+	}
+	submitters.Wait()
+
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	wait(t, done, "every event to be handled")
+
+	for channel, got := range seen {
+		if !slices.IsSorted(got) || len(got) != perChannel {
+			t.Errorf("%s handled %d events out of order: %v", channel, len(got), got)
+		}
+	}
+}
+
+func TestDispatchShard(t *testing.T) {
+	d := newDispatcher(DispatchConfig{Workers: 8, QueueSize: 8}, func(types.Event) {}, func(types.Event) {})
+	defer d.close()
+
+	tests := []struct {
+		name string
+		a, b types.Event
+	}{
+		{"same channel", types.Event{Platform: "Fake", ChannelID: "1", ServerID: "a"}, types.Event{Platform: "Fake", ChannelID: "1", ServerID: "b"}},
+		{"server without channel", types.Event{Platform: "Fake", ServerID: "a"}, types.Event{Platform: "Fake", ServerID: "a", Type: types.EventMemberJoin}},
+	}
+	for _, tt := range tests {
+		if d.shard(tt.a) != d.shard(tt.b) {
+			t.Errorf("%s: events went to different workers", tt.name)
+		}
+	}
+}
+
+func TestDispatchOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		handled []int
+		dropped []int
+	}{
+		{"Block", Block, []int{1, 2, 3, 4}, nil},
+		{"DropOldest", DropOldest, []int{1, 3, 4}, []int{2}},
+		{"DropNewest", DropNewest, []int{1, 2, 3}, []int{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu               sync.Mutex
+				handled, dropped []int
+				started          = make(chan struct{}, 4)
+				gate             = make(chan struct{})
+			)
+			// One worker with room for two queued events.
+			d := newDispatcher(DispatchConfig{Workers: 1, QueueSize: 2, Overflow: tt.policy}, func(evt types.Event) {
+				started <- struct{}{}
+				<-gate
+				mu.Lock()
+				handled = append(handled, evt.Data.(int))
+				mu.Unlock()
+			}, func(evt types.Event) {
+				mu.Lock()
+				dropped = append(dropped, evt.Data.(int))
+				mu.Unlock()
+			})
+			defer d.close()
+
+			d.submit(message("general", 1))
+			wait(t, started, "the first event to start")
+			d.submit(message("general", 2))
+			d.submit(message("general", 3))
+
+			// The queue is full now: Block waits for the worker, the
+			// other policies return at once.
+			submitted := make(chan struct{})
+			go func() {
+				d.submit(message("general", 4))
+				close(submitted)
+			}()
+			if tt.policy == Block {
+				select {
+				case <-submitted:
+					t.Fatal("Block did not wait for room in the queue")
+				case <-time.After(20 * time.Millisecond):
+				}
+			} else {
+				wait(t, submitted, "the last event to be submitted")
+			}
+			close(gate)
+			wait(t, submitted, "the last event to be submitted")
+			for range len(tt.handled) - 1 {
+				wait(t, started, "the queued events to start")
+			}
+
+			// Let the last handler finish before comparing.
+			deadline := time.Now().Add(time.Second)
+			for {
+				mu.Lock()
+				n := len(handled)
+				mu.Unlock()
+				if n == len(tt.handled) || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(handled, tt.handled) {
+				t.Errorf("handled %v, want %v", handled, tt.handled)
+			}
+			if !slices.Equal(dropped, tt.dropped) {
+				t.Errorf("dropped %v, want %v", dropped, tt.dropped)
+			}
+			if got := d.stats().Dropped; got != uint64(len(tt.dropped)) {
+				t.Errorf("stats report %d dropped, want %d", got, len(tt.dropped))
+			}
+		})
+	}
+}
+
+func TestDispatchCloseDiscardsQueued(t *testing.T) {
+	started, gate := make(chan struct{}), make(chan struct{})
+	var (
+		mu        sync.Mutex
+		discarded []int
+	)
+	d := newDispatcher(DispatchConfig{Workers: 1, QueueSize: 4}, func(evt types.Event) {
+		if evt.Data.(int) == 1 {
+			close(started)
+			<-gate
+		}
+	}, func(evt types.Event) {
+		mu.Lock()
+		discarded = append(discarded, evt.Data.(int))
+		mu.Unlock()
+	})
+
+	for n := 1; n <= 3; n++ {
+		d.submit(message("general", n))
+		if n == 1 {
+			wait(t, started, "the first event to start")
+		}
+	}
+	d.close()
+	close(gate)
+
+	// Events submitted after close are discarded too.
+	d.submit(message("general", 4))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(discarded, []int{2, 3, 4}) {
+		t.Errorf("discarded %v, want [2 3 4]", discarded)
+	}
+	if got := d.stats().Queued; got != 0 {
+		t.Errorf("stats report %d queued after close, want 0", got)
+	}
+}
+
+func TestStopReleasesQueuedEvents(t *testing.T) {
+	started, gate := make(chan struct{}), make(chan struct{})
+	b := NewWithPlatforms(nil, WithDispatch(DispatchConfig{Workers: 1, QueueSize: 4}))
+	b.OnEvent(func(evt types.Event) {
+		if evt.Data.(int) == 1 {
+			close(started)
+			<-gate
+		}
+	})
+
+	for n := 1; n <= 3; n++ {
+		b.dispatch(message("general", n))
+		if n == 1 {
+			wait(t, started, "the first event to start")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop = %v, want the deadline while a handler runs", err)
+	}
+
+	// Once the running handler returns nothing is in flight, although the
+	// queued events were never handled.
+	close(gate)
+	drained := make(chan struct{})
+	go func() { b.inflight.Wait(); close(drained) }()
+	wait(t, drained, "in-flight events to drain")
+}
@@ -1,172 +1,138 @@
-package main
+package whiskercat
 
 import (
-	"github.com/bwmarrin/discordgo"
+	"slices"
+	"sort"
+	"sync/atomic"
+
 	"github.com/luvixsocial/whiskercat/types"
-	"github.com/sentinelb51/revoltgo"
 )
 
-// OnEvent registers cross-platform event handlers and normalizes them into a common Event format.
-func OnEvent(callback func(types.Event)) {
-	registerDiscordEvents(callback)
-	registerRevoltEvents(callback)
+// handlerEntry is a registered handler.
+type handlerEntry struct {
+	priority int
+	once     bool
+	fired    atomic.Bool
+
+	match func(types.Event) bool // nil matches every event
+	call  func(types.Event)
 }
 
-func registerDiscordEvents(callback func(types.Event)) {
-	if Discord == nil {
-		return
+// HandlerOption configures a handler registered with OnEvent, Once or one
+// of the typed On* functions.
+type HandlerOption func(*handlerEntry)
+
+// Priority orders handlers: higher priorities run first, and handlers with
+// equal priority run in registration order. The default priority is 0.
+func Priority(priority int) HandlerOption {
+	return func(h *handlerEntry) {
+		h.priority = priority
 	}
+}
 
-	addDiscordHandler := func(eventName string, eventType types.EventType, context interface{}, session *discordgo.Session, bot bool, data interface{}) {
-		callback(types.Event{
-			Name:     eventName,
-			Type:     eventType,
-			Platform: "Discord",
-			Bot:      bot,
-			Context:  context,
-			Session:  session,
-			Data:     data,
-		})
+// OnlyOnce removes the handler after the first event it handles.
+func OnlyOnce() HandlerOption {
+	return func(h *handlerEntry) {
+		h.once = true
 	}
+}
 
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.MessageCreate) {
-		addDiscordHandler("MessageCreate", types.MessageCreate, e, s, e.Author.Bot, types.MessageCallback{
-			Content: e.Content,
-			Author:  convertDiscordUser(e.Author),
-		})
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.MessageUpdate) {
-		addDiscordHandler("MessageUpdate", types.MessageUpdate, e, s, e.Author.Bot, types.MessageCallback{
-			Content: e.Content,
-			Author:  convertDiscordUser(e.Author),
-		})
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.MessageDelete) {
-		addDiscordHandler("MessageDelete", types.MessageDelete, e, s, false, nil)
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.InteractionCreate) {
-		addDiscordHandler("InteractionCreate", types.InteractionCreate, e, s, false, types.InteractionCallback{
-			Name:   e.ApplicationCommandData().Name,
-			Fields: convertOptionsToMap(e.ApplicationCommandData().Options),
-			Data:   e,
-			Author: convertDiscordUser(e.Member.User),
-		})
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.TypingStart) {
-		addDiscordHandler("TypingStart", types.EventTypingStart, e, s, false, types.User{ID: e.UserID})
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.VoiceStateUpdate) {
-		addDiscordHandler("VoiceStateUpdate", types.EventVoiceStateUpdate, e, s, false, nil)
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.PresenceUpdate) {
-		addDiscordHandler("PresenceUpdate", types.EventPresenceUpdate, e, s, false, nil)
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.GuildMemberAdd) {
-		addDiscordHandler("GuildMemberAdd", types.EventGuildMemberAdd, e, s, e.User.Bot, convertDiscordUser(e.User))
-	})
-
-	Discord.AddHandler(func(s *discordgo.Session, e *discordgo.GuildMemberRemove) {
-		addDiscordHandler("GuildMemberRemove", types.EventGuildMemberRemove, e, s, e.User.Bot, convertDiscordUser(e.User))
-	})
+// OnEvent registers a cross-platform event handler. Every platform adapter
+// normalizes its gateway events into the common Event format before they reach it.
+// The returned function removes the handler; calling it again does nothing.
+func (b *Bot) OnEvent(callback func(types.Event), opts ...HandlerOption) (remove func()) {
+	return b.addHandler(nil, callback, opts)
 }
 
-func registerRevoltEvents(callback func(types.Event)) {
-	if Revolt == nil {
-		return
+// Once registers a handler that is removed after the first event.
+func (b *Bot) Once(callback func(types.Event), opts ...HandlerOption) (remove func()) {
+	return b.OnEvent(callback, append(opts, OnlyOnce())...)
+}
+
+func (b *Bot) addHandler(match func(types.Event) bool, call func(types.Event), opts []HandlerOption) func() {
+	entry := &handlerEntry{match: match, call: call}
+	for _, opt := range opts {
+		opt(entry)
 	}
 
-	addRevoltHandler := func(eventName string, eventType types.EventType, context interface{}, session *revoltgo.Session, bot bool, data interface{}) {
-		callback(types.Event{
-			Name:     eventName,
-			Type:     eventType,
-			Platform: "Revolt",
-			Bot:      bot,
-			Context:  context,
-			Session:  session,
-			Data:     data,
-		})
+	b.handlersMu.Lock()
+	// Handlers are copied on write so dispatch can iterate without the lock.
+	i := sort.Search(len(b.handlers), func(i int) bool { return b.handlers[i].priority < entry.priority })
+	b.handlers = slices.Insert(slices.Clone(b.handlers), i, entry)
+	b.handlersMu.Unlock()
+
+	return func() { b.removeHandler(entry) }
+}
+
+func (b *Bot) removeHandler(entry *handlerEntry) {
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	if i := slices.Index(b.handlers, entry); i >= 0 {
+		b.handlers = slices.Delete(slices.Clone(b.handlers), i, i+1)
 	}
+}
 
-	Revolt.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventMessage) {
-		user, _ := Revolt.User(e.Author)
-		addRevoltHandler("MessageCreate", types.MessageCreate, e, s, user.Bot != nil, types.MessageCallback{
-			Content: e.Content,
-			Author:  convertRevoltUser(user),
-		})
-	})
-
-	Revolt.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventMessageUpdate) {
-		user, _ := Revolt.User(e.Data.Author)
-		addRevoltHandler("MessageUpdate", types.MessageUpdate, e, s, user.Bot != nil, types.MessageCallback{
-			Content: e.Data.Content,
-			Author:  convertRevoltUser(user),
-		})
-	})
-
-	eventMap := map[string]types.EventType{
-		"MessageDelete":  types.MessageDelete,
-		"ReactionAdd":    types.ReactionAdd,
-		"ReactionRemove": types.ReactionRemove,
-		"TypingStart":    types.EventTypingStart,
-		"ChannelCreate":  types.EventChannelCreate,
-		"ChannelUpdate":  types.EventChannelUpdate,
-		"ChannelDelete":  types.EventChannelDelete,
-		"UserUpdate":     types.EventUserUpdate,
-		"MemberJoin":     types.EventMemberJoin,
-		"MemberLeave":    types.EventMemberLeave,
+// OnEvent registers a handler on the default bot.
+func OnEvent(callback func(types.Event), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
 	}
+	return defaultBot.OnEvent(callback, opts...)
+}
 
-	Revolt.AddHandler(func(s *revoltgo.Session, e interface{}) {
-		switch evt := e.(type) {
-		case *revoltgo.EventMessageDelete:
-			addRevoltHandler("MessageDelete", eventMap["MessageDelete"], evt, s, false, nil)
-		case *revoltgo.EventMessageReact:
-			addRevoltHandler("ReactionAdd", eventMap["ReactionAdd"], evt, s, false, nil)
-		case *revoltgo.EventMessageRemoveReaction:
-			addRevoltHandler("ReactionRemove", eventMap["ReactionRemove"], evt, s, false, nil)
-		case *revoltgo.EventChannelStartTyping:
-			addRevoltHandler("TypingStart", eventMap["TypingStart"], evt, s, false, nil)
-		case *revoltgo.EventChannelCreate:
-			addRevoltHandler("ChannelCreate", eventMap["ChannelCreate"], evt, s, false, nil)
-		case *revoltgo.EventChannelUpdate:
-			addRevoltHandler("ChannelUpdate", eventMap["ChannelUpdate"], evt, s, false, nil)
-		case *revoltgo.EventChannelDelete:
-			addRevoltHandler("ChannelDelete", eventMap["ChannelDelete"], evt, s, false, nil)
-		case *revoltgo.EventUserUpdate:
-			addRevoltHandler("UserUpdate", eventMap["UserUpdate"], evt, s, false, nil)
-		case *revoltgo.EventServerMemberJoin:
-			addRevoltHandler("MemberJoin", eventMap["MemberJoin"], evt, s, false, nil)
-		case *revoltgo.EventServerMemberLeave:
-			addRevoltHandler("MemberLeave", eventMap["MemberLeave"], evt, s, false, nil)
-		}
-	})
+// Once registers a one-shot handler on the default bot.
+func Once(callback func(types.Event), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.Once(callback, opts...)
 }
 
-func convertDiscordUser(user *discordgo.User) types.User {
-	if user == nil {
-		return types.User{}
+// dispatch is installed as the event sink of every platform adapter. It
+// hands the event to the worker pool, or handles it inline when the pool
+// is disabled.
+func (b *Bot) dispatch(evt types.Event) {
+	b.lifecycleMu.RLock()
+	if b.stopped {
+		b.lifecycleMu.RUnlock()
+		return
 	}
-	return types.User{
-		ID:       user.ID,
-		Username: user.Username,
-		Avatar:   user.AvatarURL("128"),
+	b.inflight.Add(1)
+	b.lifecycleMu.RUnlock()
+
+	if b.dispatcher != nil {
+		b.dispatcher.submit(evt)
+		return
 	}
+	b.handle(evt)
 }
 
-func convertRevoltUser(user *revoltgo.User) types.User {
-	if user == nil {
-		return types.User{}
+// handle runs the middleware chain and the handlers for one event.
+func (b *Bot) handle(evt types.Event) {
+	defer b.inflight.Done()
+
+	b.handlersMu.RLock()
+	current := b.handlers
+	middleware := b.middleware
+	b.handlersMu.RUnlock()
+
+	var handler Handler = func(evt types.Event) {
+		for _, h := range current {
+			if h.match != nil && !h.match(evt) {
+				continue
+			}
+			if h.once {
+				if !h.fired.CompareAndSwap(false, true) {
+					continue
+				}
+				b.removeHandler(h)
+			}
+			h.call(evt)
+		}
 	}
-	return types.User{
-		ID:       user.ID,
-		Username: user.Username,
-		Avatar:   user.Avatar.URL("128"),
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
 	}
+	handler(evt)
 }
@@ -0,0 +1,163 @@
+package whiskercat
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// inline returns a bot without platforms that handles events as they are
+// dispatched.
+func inline(opts ...Option) *Bot {
+	return NewWithPlatforms(nil, append([]Option{WithDispatch(DispatchConfig{})}, opts...)...)
+}
+
+func TestPriority(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []int
+		want       []int
+	}{
+		{"registration order", []int{0, 0, 0}, []int{0, 1, 2}},
+		{"higher first", []int{-1, 5, 0}, []int{1, 2, 0}},
+		{"equal priorities keep registration order", []int{1, 2, 1, 2}, []int{1, 3, 0, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := inline()
+			var got []int
+			for i, priority := range tt.priorities {
+				b.OnEvent(func(types.Event) { got = append(got, i) }, Priority(priority))
+			}
+
+			b.dispatch(message("general", 1))
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("handlers ran in order %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(b *Bot, call func(types.Event)) func()
+		remove   bool
+		want     int
+	}{
+		{"OnEvent", func(b *Bot, call func(types.Event)) func() { return b.OnEvent(call) }, false, 3},
+		{"OnEvent removed", func(b *Bot, call func(types.Event)) func() { return b.OnEvent(call) }, true, 0},
+		{"Once", func(b *Bot, call func(types.Event)) func() { return b.Once(call) }, false, 1},
+		{"Once removed before firing", func(b *Bot, call func(types.Event)) func() { return b.Once(call) }, true, 0},
+		{"OnlyOnce", func(b *Bot, call func(types.Event)) func() { return b.OnEvent(call, OnlyOnce()) }, false, 1},
+		{"typed", func(b *Bot, call func(types.Event)) func() {
+			return On(b, types.MessageCreate, func(evt types.Event, _ int) { call(evt) })
+		}, false, 3},
+		{"typed removed", func(b *Bot, call func(types.Event)) func() {
+			return On(b, types.MessageCreate, func(evt types.Event, _ int) { call(evt) })
+		}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := inline()
+			calls := 0
+			remove := tt.register(b, func(types.Event) { calls++ })
+			if tt.remove {
+				remove()
+			}
+
+			for n := range 3 {
+				b.dispatch(message("general", n))
+			}
+
+			if calls != tt.want {
+				t.Errorf("handler ran %d times, want %d", calls, tt.want)
+			}
+			// Removing again, or after Once fired, is harmless.
+			remove()
+			remove()
+			if n := len(b.handlers); n != 0 {
+				t.Errorf("%d handlers left after removal", n)
+			}
+		})
+	}
+}
+
+func TestRemoveKeepsOthers(t *testing.T) {
+	b := inline()
+	var got []string
+	b.OnEvent(func(types.Event) { got = append(got, "a") })
+	remove := b.OnEvent(func(types.Event) { got = append(got, "b") })
+	b.OnEvent(func(types.Event) { got = append(got, "c") })
+
+	remove()
+	remove()
+	b.dispatch(message("general", 1))
+
+	if !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("handlers ran: %v, want [a c]", got)
+	}
+}
+
+func TestOnceConcurrent(t *testing.T) {
+	b := NewWithPlatforms(nil, WithDispatch(DispatchConfig{Workers: 8, QueueSize: 64}))
+	defer b.dispatcher.close()
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(64)
+	b.Once(func(types.Event) { calls.Add(1) })
+	b.OnEvent(func(types.Event) { wg.Done() })
+
+	// Events in different channels run on different workers at once.
+	for n := range 64 {
+		b.dispatch(message(fmt.Sprint("channel-", n), n))
+	}
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	wait(t, done, "every event to be handled")
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Once handler ran %d times, want 1", n)
+	}
+}
+
+func TestOnSkipsOtherPayloads(t *testing.T) {
+	b := inline()
+	var got []types.MessageCallback
+	b.OnMessageCreate(func(_ types.Event, msg types.MessageCallback) { got = append(got, msg) })
+
+	events := []types.Event{
+		{Type: types.MessageCreate, Data: types.MessageCallback{Content: "hello"}},
+		{Type: types.MessageCreate, Data: "not a message"},
+		{Type: types.MessageUpdate, Data: types.MessageCallback{Content: "edited"}},
+		{Type: types.MessageCreate},
+	}
+	for _, evt := range events {
+		b.dispatch(evt)
+	}
+
+	if len(got) != 1 || got[0].Content != "hello" {
+		t.Errorf("handled %+v, want only the new message", got)
+	}
+}
+
+func TestOnMessageDelete(t *testing.T) {
+	b := inline()
+	var got []string
+	b.OnMessageDelete(func(_ types.Event, msg types.MessageCallback) { got = append(got, msg.ID) })
+
+	b.dispatch(types.Event{Type: types.MessageDelete, Data: types.MessageCallback{ID: "1", ChannelID: "general"}})
+	b.dispatch(types.Event{Type: types.MessageCreate, Data: types.MessageCallback{ID: "2", ChannelID: "general"}})
+
+	if !slices.Equal(got, []string{"1"}) {
+		t.Errorf("handled deletes of %v, want [1]", got)
+	}
+}
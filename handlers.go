@@ -0,0 +1,143 @@
+package whiskercat
+
+import "github.com/luvixsocial/whiskercat/types"
+
+// On registers a handler for events of eventType whose Data is a T, so
+// the payload arrives already type-asserted:
+//
+//	whiskercat.On(bot, types.MessageCreate, func(e types.Event, msg types.MessageCallback) {
+//		...
+//	})
+//
+// Events of eventType carrying a different payload are skipped. Like
+// OnEvent, it returns a function that removes the handler.
+func On[T any](b *Bot, eventType types.EventType, handler func(e types.Event, data T), opts ...HandlerOption) (remove func()) {
+	match := func(evt types.Event) bool {
+		_, ok := evt.Data.(T)
+		return ok && evt.Type == eventType
+	}
+	return b.addHandler(match, func(evt types.Event) { handler(evt, evt.Data.(T)) }, opts)
+}
+
+// OnMessageCreate registers a handler for new messages.
+func (b *Bot) OnMessageCreate(handler func(e types.Event, msg types.MessageCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.MessageCreate, handler, opts...)
+}
+
+// OnMessageUpdate registers a handler for edited messages.
+func (b *Bot) OnMessageUpdate(handler func(e types.Event, msg types.MessageCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.MessageUpdate, handler, opts...)
+}
+
+// OnMessageDelete registers a handler for deleted messages. Usually only
+// the message's ID and channel are known; Discord also reports the content
+// of messages it had cached.
+func (b *Bot) OnMessageDelete(handler func(e types.Event, msg types.MessageCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.MessageDelete, handler, opts...)
+}
+
+// OnInteractionCreate registers a handler for slash commands and other
+// interactions.
+func (b *Bot) OnInteractionCreate(handler func(e types.Event, interaction types.InteractionCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.InteractionCreate, handler, opts...)
+}
+
+// OnReactionAdd registers a handler for added reactions.
+func (b *Bot) OnReactionAdd(handler func(e types.Event, reaction types.ReactionCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.ReactionAdd, handler, opts...)
+}
+
+// OnReactionRemove registers a handler for removed reactions.
+func (b *Bot) OnReactionRemove(handler func(e types.Event, reaction types.ReactionCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.ReactionRemove, handler, opts...)
+}
+
+// OnMemberJoin registers a handler for members joining a server or IRC
+// channel.
+func (b *Bot) OnMemberJoin(handler func(e types.Event, member types.MemberCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.EventMemberJoin, handler, opts...)
+}
+
+// OnMemberLeave registers a handler for members leaving or being removed
+// from a server or IRC channel.
+func (b *Bot) OnMemberLeave(handler func(e types.Event, member types.MemberCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.EventMemberLeave, handler, opts...)
+}
+
+// OnMemberUpdate registers a handler for members whose nickname, roles or
+// timeout changed.
+func (b *Bot) OnMemberUpdate(handler func(e types.Event, member types.MemberCallback), opts ...HandlerOption) (remove func()) {
+	return On(b, types.EventMemberUpdate, handler, opts...)
+}
+
+// OnMessageCreate registers a new message handler on the default bot.
+func OnMessageCreate(handler func(e types.Event, msg types.MessageCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnMessageCreate(handler, opts...)
+}
+
+// OnMessageUpdate registers an edited message handler on the default bot.
+func OnMessageUpdate(handler func(e types.Event, msg types.MessageCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnMessageUpdate(handler, opts...)
+}
+
+// OnMessageDelete registers a deleted message handler on the default bot.
+func OnMessageDelete(handler func(e types.Event, msg types.MessageCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnMessageDelete(handler, opts...)
+}
+
+// OnInteractionCreate registers an interaction handler on the default bot.
+func OnInteractionCreate(handler func(e types.Event, interaction types.InteractionCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnInteractionCreate(handler, opts...)
+}
+
+// OnReactionAdd registers an added reaction handler on the default bot.
+func OnReactionAdd(handler func(e types.Event, reaction types.ReactionCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnReactionAdd(handler, opts...)
+}
+
+// OnReactionRemove registers a removed reaction handler on the default bot.
+func OnReactionRemove(handler func(e types.Event, reaction types.ReactionCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnReactionRemove(handler, opts...)
+}
+
+// OnMemberJoin registers a member join handler on the default bot.
+func OnMemberJoin(handler func(e types.Event, member types.MemberCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnMemberJoin(handler, opts...)
+}
+
+// OnMemberLeave registers a member leave handler on the default bot.
+func OnMemberLeave(handler func(e types.Event, member types.MemberCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnMemberLeave(handler, opts...)
+}
+
+// OnMemberUpdate registers a member update handler on the default bot.
+func OnMemberUpdate(handler func(e types.Event, member types.MemberCallback), opts ...HandlerOption) (remove func()) {
+	if defaultBot == nil {
+		return func() {}
+	}
+	return defaultBot.OnMemberUpdate(handler, opts...)
+}
@@ -0,0 +1,105 @@
+package whiskercat_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/luvixsocial/whiskercat/whiskercattest"
+)
+
+// fastPolicy reconnects almost at once and reports a platform Down after
+// three failed attempts.
+var fastPolicy = whiskercat.ReconnectPolicy{
+	InitialDelay: time.Millisecond,
+	MaxDelay:     2 * time.Millisecond,
+	Multiplier:   2,
+	DownAfter:    3,
+}
+
+// eventually fails the test unless the platform's health matches want
+// within a second.
+func eventually(t *testing.T, b *whiskercat.Bot, want func(whiskercat.PlatformHealth) bool, what string) whiskercat.PlatformHealth {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		h := b.HealthReport()[whiskercattest.Name]
+		if want(h) {
+			return h
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s; health is %+v", what, h)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReconnectHealth(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	b := newBot(t, fake, whiskercat.WithReconnectPolicy(fastPolicy))
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if h := b.Health(whiskercattest.Name); h != types.Healthy {
+		t.Fatalf("Health after Start = %v, want %v", h, types.Healthy)
+	}
+
+	// Each reconnect attempt returns the next result sent by the test.
+	results := make(chan error)
+	fake.OnConnect(func(context.Context) error { return <-results })
+
+	fake.Disconnect(context.Background())
+	if h := b.Health(whiskercattest.Name); h != types.Degraded {
+		t.Fatalf("Health after a disconnect = %v, want %v", h, types.Degraded)
+	}
+
+	for attempt := 1; attempt <= fastPolicy.DownAfter; attempt++ {
+		err := errors.New("connection refused")
+		results <- err
+
+		want := types.Degraded
+		if attempt == fastPolicy.DownAfter {
+			want = types.Down
+		}
+		h := eventually(t, b, func(h whiskercat.PlatformHealth) bool { return h.Attempts == attempt }, "the failed attempt")
+		if h.State != want || h.LastError != err {
+			t.Errorf("after %d failed attempts: health = %+v, want %v with the last error", attempt, h, want)
+		}
+	}
+
+	results <- nil
+	h := eventually(t, b, func(h whiskercat.PlatformHealth) bool { return h.State == types.Healthy }, "the reconnect")
+	if h.Attempts != 0 {
+		t.Errorf("Attempts = %d after reconnecting, want 0", h.Attempts)
+	}
+	if !fake.Connected() {
+		t.Error("platform is not connected after reconnecting")
+	}
+}
+
+func TestReconnectStops(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	b := newBot(t, fake, whiskercat.WithReconnectPolicy(fastPolicy))
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	refused := errors.New("connection refused")
+	fake.OnConnect(func(context.Context) error { return refused })
+	fake.Disconnect(context.Background())
+	eventually(t, b, func(h whiskercat.PlatformHealth) bool { return h.State == types.Down }, "the platform to go down")
+
+	if err := b.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Attempts stop with the bot, once one that was under way finishes.
+	time.Sleep(5 * time.Millisecond)
+	attempts := b.HealthReport()[whiskercattest.Name].Attempts
+	time.Sleep(20 * time.Millisecond)
+	if h := b.HealthReport()[whiskercattest.Name]; h.Attempts != attempts || h.State != types.Down {
+		t.Errorf("health after Stop = %+v, want %d attempts and Down", h, attempts)
+	}
+}
@@ -1,213 +1,113 @@
-// Package functions provides core utility helpers for handling
+// Package whiskercat provides core utility helpers for handling
 // Discord and Revolt bot operations across platforms, including messaging,
 // slash commands, logging, cooldown management, and more.
-package main
+package whiskercat
 
 import (
 	"fmt"
 	"log"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/platform"
 	"github.com/luvixsocial/whiskercat/types"
-	"github.com/sentinelb51/revoltgo"
 	"slices"
 )
 
-var (
-	cooldowns     = make(map[string]time.Time)
-	cooldownMutex sync.Mutex
-)
-
-func ptr[T any](v T) *T {
+// Ptr returns a pointer to v, which is handy for optional Embed fields.
+func Ptr[T any](v T) *T {
 	return &v
 }
 
-func convertOptionsToMap(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]string {
-	result := make(map[string]string)
-	for _, opt := range options {
-		if opt != nil && opt.Value != nil {
-			result[opt.Name] = fmt.Sprintf("%v", opt.Value)
-		}
-	}
-	return result
-}
+var errNotConfigured = fmt.Errorf("whiskercat: Config has not been called")
 
-func convertToDiscordEmbed(embed *types.Embed) *discordgo.MessageEmbed {
-	if embed == nil {
-		return nil
+// lookupPlatform returns the bot's adapter for a platform name.
+func (b *Bot) lookupPlatform(name string) (platform.Platform, error) {
+	if registered, ok := b.platformNames[types.PlatformKey(name)]; ok {
+		return b.platforms[registered], nil
 	}
-
-	em := &discordgo.MessageEmbed{
-		Title:       embed.Title,
-		Description: embed.Description,
-		Color:       embed.Color,
+	if _, registered := platform.Lookup(name); registered {
+		return nil, fmt.Errorf("%w: %s", platform.ErrNotConfigured, name)
 	}
+	return nil, fmt.Errorf("unsupported platform %q", name)
+}
 
-	if embed.URL != nil {
-		em.URL = *embed.URL
-	}
-	if embed.IconURL != nil {
-		em.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: *embed.IconURL}
-	}
-	if embed.PhotoURL != nil {
-		em.Image = &discordgo.MessageEmbedImage{URL: *embed.PhotoURL}
-	}
-	if embed.Footer != nil {
-		em.Footer = &discordgo.MessageEmbedFooter{
-			Text:    embed.Footer.Text,
-			IconURL: embed.Footer.PhotoURL,
-		}
+// Respond replies to an event on the platform it came from. When edit is
+// non-nil the message with that ID is edited instead.
+func Respond(e types.Event, content string, embed *types.Embed, edit *string) (*types.SentMessage, error) {
+	if e.Source == nil {
+		return nil, fmt.Errorf("event from %q has no source platform", e.Platform)
 	}
+	return e.Source.Respond(e, types.MessageSend{Content: content, Embed: embed}, edit)
+}
 
-	if embed.Fields != nil {
-		for _, f := range *embed.Fields {
-			em.Fields = append(em.Fields, &discordgo.MessageEmbedField{
-				Name:   f.Name,
-				Value:  f.Value,
-				Inline: f.Inline,
-			})
-		}
+// SendMessage posts a message to a channel on the named platform.
+func (b *Bot) SendMessage(platform, channelID, content string, embed *types.Embed) (*types.SentMessage, error) {
+	p, err := b.lookupPlatform(platform)
+	if err != nil {
+		return nil, err
 	}
-	return em
+	return p.Send(channelID, types.MessageSend{Content: content, Embed: embed})
 }
 
-func convertToRevoltEmbed(embed *types.Embed) *revoltgo.MessageEmbed {
-	if embed == nil {
-		return nil
+// EditMessage edits a message on the named platform.
+func (b *Bot) EditMessage(platform, channelID, messageID, content string, embed *types.Embed) (*types.SentMessage, error) {
+	p, err := b.lookupPlatform(platform)
+	if err != nil {
+		return nil, err
 	}
+	return p.Edit(channelID, messageID, types.MessageSend{Content: content, Embed: embed})
+}
 
-	description := embed.Description
-
-	// Append fields as Markdown
-	if embed.Fields != nil && len(*embed.Fields) > 0 {
-		var fieldMarkdown strings.Builder
-		fieldMarkdown.WriteString("\n\n")
-		for _, f := range *embed.Fields {
-			fieldMarkdown.WriteString(fmt.Sprintf("**%s**\n%s\n\n", f.Name, f.Value))
-		}
-		description += fieldMarkdown.String()
+// DeleteMessage deletes a message on the named platform.
+func (b *Bot) DeleteMessage(platform, channelID, messageID string) error {
+	p, err := b.lookupPlatform(platform)
+	if err != nil {
+		return err
 	}
+	return p.Delete(channelID, messageID)
+}
 
-	em := &revoltgo.MessageEmbed{
-		Title:       embed.Title,
-		Description: description,
-		Colour:      fmt.Sprintf("#%06X", embed.Color),
+// AddReaction reacts to a message on the named platform.
+func (b *Bot) AddReaction(platform, channelID, messageID, emoji string) error {
+	p, err := b.lookupPlatform(platform)
+	if err != nil {
+		return err
 	}
+	return p.React(channelID, messageID, emoji)
+}
 
-	if embed.URL != nil {
-		em.URL = *embed.URL
-	}
-	if embed.PhotoURL != nil {
-		em.Image = &revoltgo.MessageEmbedImage{URL: *embed.PhotoURL}
+// SendMessage posts a message using the default bot.
+func SendMessage(platform, channelID, content string, embed *types.Embed) (*types.SentMessage, error) {
+	if defaultBot == nil {
+		return nil, errNotConfigured
 	}
-
-	return em
+	return defaultBot.SendMessage(platform, channelID, content, embed)
 }
 
-func Respond(e types.Event, content string, embed *types.Embed, edit *string) (interface{}, error) {
-	switch e.Platform {
-	case "Discord":
-		s := e.Session.(*discordgo.Session)
-		switch ctx := e.Context.(type) {
-		case *discordgo.MessageCreate:
-			if edit != nil {
-				em := &discordgo.MessageEdit{ID: *edit, Channel: ctx.ChannelID, Content: &content}
-				if embed != nil {
-					em.Embeds = &[]*discordgo.MessageEmbed{convertToDiscordEmbed(embed)}
-				}
-				return s.ChannelMessageEditComplex(em)
-			}
-			msg := &discordgo.MessageSend{Content: content, Reference: &discordgo.MessageReference{MessageID: ctx.ID, ChannelID: ctx.ChannelID, GuildID: ctx.GuildID}}
-			if embed != nil {
-				msg.Embeds = []*discordgo.MessageEmbed{convertToDiscordEmbed(embed)}
-			}
-			return s.ChannelMessageSendComplex(ctx.ChannelID, msg)
-
-		case *discordgo.InteractionCreate:
-			if edit != nil {
-				ed := &discordgo.WebhookEdit{Content: &content}
-				if embed != nil {
-					ed.Embeds = &[]*discordgo.MessageEmbed{convertToDiscordEmbed(embed)}
-				}
-				return s.InteractionResponseEdit(ctx.Interaction, ed)
-			}
-			r := &discordgo.InteractionResponseData{Content: content}
-			if embed != nil {
-				r.Embeds = []*discordgo.MessageEmbed{convertToDiscordEmbed(embed)}
-			}
-			return nil, s.InteractionRespond(ctx.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: r})
-		}
-
-	case "Revolt":
-		s := e.Session.(*revoltgo.Session)
-		var channelID string
-		switch ctx := e.Context.(type) {
-		case *revoltgo.EventMessage:
-			channelID = ctx.Channel
-		case *revoltgo.EventMessageUpdate:
-			channelID = ctx.Data.Channel
-		case *revoltgo.EventMessageReact, *revoltgo.EventMessageRemoveReaction:
-			channelID = ctx.(interface{ GetChannelID() string }).GetChannelID()
-		default:
-			return nil, fmt.Errorf("unsupported Revolt context")
-		}
-		if edit != nil {
-			em := revoltgo.MessageEditData{Content: content}
-			if embed != nil {
-				em.Embeds = []*revoltgo.MessageEmbed{convertToRevoltEmbed(embed)}
-			}
-			return s.ChannelMessageEdit(channelID, *edit, em)
-		}
-		msg := revoltgo.MessageSend{Content: content}
-		if embed != nil {
-			msg.Embeds = []*revoltgo.MessageEmbed{convertToRevoltEmbed(embed)}
-		}
-		return s.ChannelMessageSend(channelID, msg)
+// EditMessage edits a message using the default bot.
+func EditMessage(platform, channelID, messageID, content string, embed *types.Embed) (*types.SentMessage, error) {
+	if defaultBot == nil {
+		return nil, errNotConfigured
 	}
-
-	return nil, fmt.Errorf("unsupported platform or context")
+	return defaultBot.EditMessage(platform, channelID, messageID, content, embed)
 }
 
-func SendMessage(platform, channelID, content string, embed *types.Embed) (interface{}, error) {
-	switch platform {
-	case "Discord":
-		msg := &discordgo.MessageSend{Content: content}
-		if embed != nil {
-			msg.Embeds = []*discordgo.MessageEmbed{convertToDiscordEmbed(embed)}
-		}
-		return Discord.ChannelMessageSendComplex(channelID, msg)
-
-	case "Revolt":
-		msg := revoltgo.MessageSend{Content: content}
-		if embed != nil {
-			msg.Embeds = []*revoltgo.MessageEmbed{convertToRevoltEmbed(embed)}
-		}
-		return Revolt.ChannelMessageSend(channelID, msg)
-	default:
-		return nil, fmt.Errorf("unsupported platform")
+// DeleteMessage deletes a message using the default bot.
+func DeleteMessage(platform, channelID, messageID string) error {
+	if defaultBot == nil {
+		return errNotConfigured
 	}
+	return defaultBot.DeleteMessage(platform, channelID, messageID)
 }
 
-func EditMessage(platform, channelID, messageID, content string, embed *types.Embed) (interface{}, error) {
-	switch platform {
-	case "Discord":
-		em := &discordgo.MessageEdit{ID: messageID, Channel: channelID, Content: &content}
-		if embed != nil {
-			em.Embeds = &[]*discordgo.MessageEmbed{convertToDiscordEmbed(embed)}
-		}
-		return Discord.ChannelMessageEditComplex(em)
-	case "Revolt":
-		em := revoltgo.MessageEditData{Content: content}
-		if embed != nil {
-			em.Embeds = []*revoltgo.MessageEmbed{convertToRevoltEmbed(embed)}
-		}
-		return Revolt.ChannelMessageEdit(channelID, messageID, em)
-	default:
-		return nil, fmt.Errorf("unsupported platform")
+// AddReaction reacts to a message using the default bot.
+func AddReaction(platform, channelID, messageID, emoji string) error {
+	if defaultBot == nil {
+		return errNotConfigured
 	}
+	return defaultBot.AddReaction(platform, channelID, messageID, emoji)
 }
 
 func DeferInteraction(s *discordgo.Session, interaction *discordgo.Interaction) error {
@@ -238,21 +138,7 @@ func GetAuthor(e types.Event) types.User {
 	return types.User{}
 }
 
-func GetChannelID(e types.Event) string {
-	switch ctx := e.Context.(type) {
-	case *discordgo.MessageCreate:
-		return ctx.ChannelID
-	case *discordgo.InteractionCreate:
-		return ctx.ChannelID
-	case *revoltgo.EventMessage:
-		return ctx.Channel
-	case *revoltgo.EventMessageUpdate:
-		return ctx.Data.Channel
-	case *revoltgo.EventMessageReact:
-		return ctx.ChannelID
-	}
-	return ""
-}
+func GetChannelID(e types.Event) string { return e.ChannelID }
 
 func NewCommand(name, desc string, opts ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{Name: name, Description: desc, Options: opts}
@@ -295,15 +181,7 @@ func EnsureSlashCommands(s *discordgo.Session, appID, guildID string, cmds []*di
 	return existing, nil
 }
 
-func GetGuildID(e types.Event) string {
-	switch ctx := e.Context.(type) {
-	case *discordgo.MessageCreate:
-		return ctx.GuildID
-	case *discordgo.InteractionCreate:
-		return ctx.GuildID
-	}
-	return ""
-}
+func GetGuildID(e types.Event) string { return e.ServerID }
 
 func GetUsername(e types.Event) string {
 	u := GetAuthor(e)
@@ -311,16 +189,26 @@ func GetUsername(e types.Event) string {
 }
 func IsAdmin(id string, admins []string) bool { return slices.Contains(admins, id) }
 
-func Cooldown(key string, d time.Duration) bool {
-	cooldownMutex.Lock()
-	defer cooldownMutex.Unlock()
-	if until, exists := cooldowns[key]; exists && time.Now().Before(until) {
+// Cooldown reports whether key is still cooling down, starting a new
+// cooldown of d if it is not.
+func (b *Bot) Cooldown(key string, d time.Duration) bool {
+	b.cooldownMutex.Lock()
+	defer b.cooldownMutex.Unlock()
+	if until, exists := b.cooldowns[key]; exists && time.Now().Before(until) {
 		return true
 	}
-	cooldowns[key] = time.Now().Add(d)
+	b.cooldowns[key] = time.Now().Add(d)
 	return false
 }
 
+// Cooldown checks a cooldown on the default bot.
+func Cooldown(key string, d time.Duration) bool {
+	if defaultBot == nil {
+		return false
+	}
+	return defaultBot.Cooldown(key, d)
+}
+
 func Retry(fn func() error, attempts int) error {
 	var err error
 	for i := 0; i < attempts; i++ {
@@ -0,0 +1,39 @@
+package whiskercat_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/whiskercattest"
+)
+
+func TestSendMessagePlatformName(t *testing.T) {
+	fake := whiskercattest.NewPlatform("Fake")
+	b := whiskercat.NewWithPlatforms([]platform.Platform{fake}, whiskercat.WithDispatch(whiskercat.DispatchConfig{}))
+
+	tests := []struct {
+		platform string
+		want     error // nil if the message must be sent
+	}{
+		{"Fake", nil},
+		// References spell platform names in lower case.
+		{"fake", nil},
+		// Registered, but the bot was not configured for it.
+		{"discord", platform.ErrNotConfigured},
+		{"Discord", platform.ErrNotConfigured},
+	}
+	for _, tt := range tests {
+		if _, err := b.SendMessage(tt.platform, "general", "hi", nil); !errors.Is(err, tt.want) {
+			t.Errorf("SendMessage on %q: err = %v, want %v", tt.platform, err, tt.want)
+		}
+	}
+	if n := len(fake.Actions()); n != 2 {
+		t.Errorf("sent %d messages, want 2", n)
+	}
+
+	if _, err := b.SendMessage("carrier-pigeon", "general", "hi", nil); err == nil || errors.Is(err, platform.ErrNotConfigured) {
+		t.Errorf("SendMessage on an unknown platform: err = %v, want unsupported", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package link connects the accounts a person has on different platforms.
+//
+// A link is made in two steps: the person asks for a one-time code on one
+// platform and confirms it from their account on another. Links are kept in
+// a Store, so they survive restarts when a persistent store is used.
+package link
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// DefaultCodeTTL is how long a code stays valid when New is given a zero TTL.
+const DefaultCodeTTL = 10 * time.Minute
+
+// codeAlphabet leaves out characters that are easily confused, like 0 and O.
+const (
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+)
+
+var (
+	// ErrNotLinked is returned when an account has no link to the platform
+	// asked for.
+	ErrNotLinked = errors.New("account not linked")
+
+	// ErrInvalidCode is returned when confirming a code that was never
+	// issued, has expired or was already used.
+	ErrInvalidCode = errors.New("invalid or expired link code")
+
+	// ErrSamePlatform is returned when a code is confirmed on the platform
+	// it was issued on.
+	ErrSamePlatform = errors.New("link code must be confirmed on another platform")
+)
+
+// Store persists links. Links are symmetric, and an account is linked to at
+// most one account on each other platform. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Linked returns the account linked to user on platform, or
+	// ErrNotLinked.
+	Linked(ctx context.Context, user types.UserRef, platform string) (types.UserRef, error)
+
+	// Link links a and b, replacing any link either had to the other's
+	// platform.
+	Link(ctx context.Context, a, b types.UserRef) error
+
+	// Unlink removes every link of user.
+	Unlink(ctx context.Context, user types.UserRef) error
+}
+
+// Linker issues and confirms link codes and answers lookups from its Store.
+// It is safe for concurrent use.
+type Linker struct {
+	store Store
+
+	// mu makes confirming a code and consuming it one step.
+	mu    sync.Mutex
+	codes *cache.Cache[string, types.UserRef]
+}
+
+// New creates a Linker backed by store whose codes are valid for ttl. A zero
+// ttl selects DefaultCodeTTL.
+func New(store Store, ttl time.Duration) *Linker {
+	if ttl <= 0 {
+		ttl = DefaultCodeTTL
+	}
+	return &Linker{
+		store: store,
+		codes: cache.New[string, types.UserRef](0, ttl),
+	}
+}
+
+// Store returns the store the Linker reads and writes.
+func (l *Linker) Store() Store {
+	return l.store
+}
+
+// Issue returns a new code that links user to whichever account confirms it
+// on another platform. The code should only be shown to user, such as in a
+// direct message.
+func (l *Linker) Issue(user types.UserRef) (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	code := string(b)
+	l.codes.Set(code, user)
+	return code, nil
+}
+
+// Confirm links user to the account the code was issued to, which it
+// returns. Codes are case-insensitive and can only be used once.
+func (l *Linker) Confirm(ctx context.Context, code string, user types.UserRef) (types.UserRef, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	l.mu.Lock()
+	issuer, ok := l.codes.Get(code)
+	if ok && issuer.Platform != user.Platform {
+		l.codes.Delete(code)
+	}
+	l.mu.Unlock()
+
+	switch {
+	case !ok:
+		return types.UserRef{}, ErrInvalidCode
+	case issuer.Platform == user.Platform:
+		return types.UserRef{}, ErrSamePlatform
+	}
+	if err := l.store.Link(ctx, issuer, user); err != nil {
+		return types.UserRef{}, err
+	}
+	return issuer, nil
+}
+
+// Linked returns the account linked to user on platform, or ErrNotLinked.
+func (l *Linker) Linked(ctx context.Context, user types.UserRef, platform string) (types.UserRef, error) {
+	return l.store.Linked(ctx, user, types.PlatformKey(platform))
+}
+
+// Unlink removes every link of user.
+func (l *Linker) Unlink(ctx context.Context, user types.UserRef) error {
+	return l.store.Unlink(ctx, user)
+}
@@ -0,0 +1,60 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+var (
+	alice    = types.UserRef{Platform: "discord", ID: "1"}
+	aliceIRC = types.UserRef{Platform: "irc", ID: "alice"}
+	bob      = types.UserRef{Platform: "discord", ID: "2"}
+)
+
+func TestConfirm(t *testing.T) {
+	ctx := context.Background()
+	l := New(NewMemoryStore(), 0)
+	code, err := l.Issue(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Confirming on the issuing platform fails but keeps the code.
+	if _, err := l.Confirm(ctx, code, bob); !errors.Is(err, ErrSamePlatform) {
+		t.Errorf("Confirm on the same platform: err = %v, want ErrSamePlatform", err)
+	}
+
+	// Codes are case-insensitive and ignore surrounding spaces.
+	issuer, err := l.Confirm(ctx, " "+strings.ToLower(code)+"\n", aliceIRC)
+	if err != nil || issuer != alice {
+		t.Fatalf("Confirm = %v, %v; want %v", issuer, err, alice)
+	}
+	if linked, err := l.Linked(ctx, aliceIRC, "Discord"); err != nil || linked != alice {
+		t.Errorf("Linked = %v, %v; want %v", linked, err, alice)
+	}
+
+	// Codes can only be used once.
+	if _, err := l.Confirm(ctx, code, types.UserRef{Platform: "matrix", ID: "@alice:example.org"}); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Confirm of a used code: err = %v, want ErrInvalidCode", err)
+	}
+	if _, err := l.Confirm(ctx, "NOTACODE", aliceIRC); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Confirm of an unknown code: err = %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestConfirmExpired(t *testing.T) {
+	l := New(NewMemoryStore(), time.Millisecond)
+	code, err := l.Issue(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := l.Confirm(context.Background(), code, aliceIRC); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Confirm of an expired code: err = %v, want ErrInvalidCode", err)
+	}
+}
@@ -0,0 +1,191 @@
+package link
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// MemoryStore keeps links in memory. They are lost when the process exits.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	links map[types.UserRef]map[string]types.UserRef // By account, then linked platform
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{links: make(map[types.UserRef]map[string]types.UserRef)}
+}
+
+// Linked implements Store.
+func (s *MemoryStore) Linked(_ context.Context, user types.UserRef, platform string) (types.UserRef, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if linked, ok := s.links[user][platform]; ok {
+		return linked, nil
+	}
+	return types.UserRef{}, ErrNotLinked
+}
+
+// Link implements Store.
+func (s *MemoryStore) Link(_ context.Context, a, b types.UserRef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.link(a, b)
+	return nil
+}
+
+// Unlink implements Store.
+func (s *MemoryStore) Unlink(_ context.Context, user types.UserRef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unlink(user)
+	return nil
+}
+
+func (s *MemoryStore) link(a, b types.UserRef) {
+	s.unlinkPlatform(a, b.Platform)
+	s.unlinkPlatform(b, a.Platform)
+	s.set(a, b)
+	s.set(b, a)
+}
+
+func (s *MemoryStore) set(from, to types.UserRef) {
+	if s.links[from] == nil {
+		s.links[from] = make(map[string]types.UserRef)
+	}
+	s.links[from][to.Platform] = to
+}
+
+// unlinkPlatform removes user's link to platform from both sides.
+func (s *MemoryStore) unlinkPlatform(user types.UserRef, platform string) {
+	linked, ok := s.links[user][platform]
+	if !ok {
+		return
+	}
+	s.remove(user, platform)
+	s.remove(linked, user.Platform)
+}
+
+func (s *MemoryStore) remove(from types.UserRef, platform string) {
+	delete(s.links[from], platform)
+	if len(s.links[from]) == 0 {
+		delete(s.links, from)
+	}
+}
+
+func (s *MemoryStore) unlink(user types.UserRef) {
+	for platform := range s.links[user] {
+		s.unlinkPlatform(user, platform)
+	}
+}
+
+// clone returns a copy of the links.
+func (s *MemoryStore) clone() *MemoryStore {
+	c := NewMemoryStore()
+	for from, links := range s.links {
+		c.links[from] = maps.Clone(links)
+	}
+	return c
+}
+
+// pairs returns every link once.
+func (s *MemoryStore) pairs() [][2]types.UserRef {
+	var pairs [][2]types.UserRef
+	for from, links := range s.links {
+		for _, to := range links {
+			if from.String() < to.String() {
+				pairs = append(pairs, [2]types.UserRef{from, to})
+			}
+		}
+	}
+	return pairs
+}
+
+// FileStore keeps links in memory and saves them to a JSON file after every
+// change.
+type FileStore struct {
+	path   string
+	memory *MemoryStore
+}
+
+// NewFileStore creates a FileStore saving to path, loading the links already
+// saved there. A missing file is created on the first change.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, memory: NewMemoryStore()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pairs [][2]types.UserRef
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		s.memory.link(pair[0], pair[1])
+	}
+	return s, nil
+}
+
+// Linked implements Store.
+func (s *FileStore) Linked(ctx context.Context, user types.UserRef, platform string) (types.UserRef, error) {
+	return s.memory.Linked(ctx, user, platform)
+}
+
+// Link implements Store.
+func (s *FileStore) Link(_ context.Context, a, b types.UserRef) error {
+	return s.update(func(m *MemoryStore) { m.link(a, b) })
+}
+
+// Unlink implements Store.
+func (s *FileStore) Unlink(_ context.Context, user types.UserRef) error {
+	return s.update(func(m *MemoryStore) { m.unlink(user) })
+}
+
+// update applies change to a copy of the links and saves it, and only then
+// makes it current, so a failed save changes nothing.
+func (s *FileStore) update(change func(*MemoryStore)) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+
+	next := s.memory.clone()
+	change(next)
+	if err := s.save(next.pairs()); err != nil {
+		return err
+	}
+	s.memory.links = next.links
+	return nil
+}
+
+// save writes the links to a temporary file and renames it over the old
+// one, so a crash never leaves a partially written file behind.
+func (s *FileStore) save(pairs [][2]types.UserRef) error {
+	data, err := json.MarshalIndent(pairs, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
@@ -0,0 +1,81 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+func TestFileStoreReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "links.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Link(ctx, alice, aliceIRC); err != nil {
+		t.Fatal(err)
+	}
+	bobIRC := types.UserRef{Platform: "irc", ID: "bob"}
+	if err := s.Link(ctx, bob, bobIRC); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unlink(ctx, bob); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked, err := reloaded.Linked(ctx, alice, "irc"); err != nil || linked != aliceIRC {
+		t.Errorf("Linked(alice, irc) = %v, %v; want %v", linked, err, aliceIRC)
+	}
+	if linked, err := reloaded.Linked(ctx, aliceIRC, "discord"); err != nil || linked != alice {
+		t.Errorf("Linked(aliceIRC, discord) = %v, %v; want %v", linked, err, alice)
+	}
+	if _, err := reloaded.Linked(ctx, bobIRC, "discord"); !errors.Is(err, ErrNotLinked) {
+		t.Errorf("Linked of an unlinked account: err = %v, want ErrNotLinked", err)
+	}
+}
+
+func TestFileStoreFailedSave(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "links.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Link(ctx, alice, aliceIRC); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in the file's place makes saving fail.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Link(ctx, bob, aliceIRC); err == nil {
+		t.Fatal("Link succeeded without saving")
+	}
+	if err := s.Unlink(ctx, alice); err == nil {
+		t.Fatal("Unlink succeeded without saving")
+	}
+
+	// Neither change was applied.
+	if linked, err := s.Linked(ctx, aliceIRC, "discord"); err != nil || linked != alice {
+		t.Errorf("Linked after failed saves = %v, %v; want %v", linked, err, alice)
+	}
+	if _, err := s.Linked(ctx, bob, "irc"); !errors.Is(err, ErrNotLinked) {
+		t.Errorf("Linked of the unsaved link: err = %v, want ErrNotLinked", err)
+	}
+	if matches, _ := filepath.Glob(path + ".*.tmp"); len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
@@ -0,0 +1,41 @@
+package whiskercat
+
+import (
+	"context"
+
+	"github.com/luvixsocial/whiskercat/link"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Links returns the bot's account linker, which issues and confirms link
+// codes.
+func (b *Bot) Links() *link.Linker {
+	return b.links
+}
+
+// LinkedUser returns the account linked to user on the named platform,
+// looked up like User. It returns link.ErrNotLinked if there is none.
+func (b *Bot) LinkedUser(ctx context.Context, user types.UserRef, platform string) (types.User, error) {
+	linked, err := b.links.Linked(ctx, user, platform)
+	if err != nil {
+		return types.User{}, err
+	}
+	return b.User(ctx, linked.Platform, linked.ID)
+}
+
+// Links returns the default bot's account linker, or nil if Config has not
+// been called.
+func Links() *link.Linker {
+	if defaultBot == nil {
+		return nil
+	}
+	return defaultBot.links
+}
+
+// LinkedUser looks up a linked account using the default bot.
+func LinkedUser(ctx context.Context, user types.UserRef, platform string) (types.User, error) {
+	if defaultBot == nil {
+		return types.User{}, errNotConfigured
+	}
+	return defaultBot.LinkedUser(ctx, user, platform)
+}
@@ -0,0 +1,93 @@
+package whiskercat
+
+import (
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Handler handles a normalized event.
+type Handler func(types.Event)
+
+// Middleware wraps the dispatch of every event. It may act before or after
+// calling next, or not call next at all to stop the event from reaching
+// the handlers.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the bot's chain. The first middleware added is
+// the outermost one and sees each event first.
+func (b *Bot) Use(middleware ...Middleware) {
+	b.handlersMu.Lock()
+	b.middleware = append(b.middleware, middleware...)
+	b.handlersMu.Unlock()
+}
+
+// Use appends middleware to the default bot's chain.
+func Use(middleware ...Middleware) {
+	if defaultBot != nil {
+		defaultBot.Use(middleware...)
+	}
+}
+
+// Filter only lets events for which keep returns true through, which is
+// handy for blacklists.
+func Filter(keep func(types.Event) bool) Middleware {
+	return func(next Handler) Handler {
+		return func(evt types.Event) {
+			if keep(evt) {
+				next(evt)
+			}
+		}
+	}
+}
+
+// IgnoreBots drops events triggered by bots, including the bot itself.
+func IgnoreBots() Middleware {
+	return Filter(func(evt types.Event) bool { return !evt.Bot })
+}
+
+// LogEvents logs every event with LogEvent before handling it.
+func LogEvents() Middleware {
+	return func(next Handler) Handler {
+		return func(evt types.Event) {
+			LogEvent(evt)
+			next(evt)
+		}
+	}
+}
+
+// Recover logs panics raised while handling an event, with their stack
+// trace, instead of crashing the bot.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(evt types.Event) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[%s:%s] handler panicked: %v\n%s", evt.Platform, evt.Type, r, debug.Stack())
+				}
+			}()
+			next(evt)
+		}
+	}
+}
+
+// Timing measures how long the rest of the chain takes for each event and
+// passes it to report. A nil report logs events slower than a second.
+func Timing(report func(evt types.Event, elapsed time.Duration)) Middleware {
+	if report == nil {
+		report = func(evt types.Event, elapsed time.Duration) {
+			if elapsed > time.Second {
+				log.Printf("[%s:%s] handled in %s\n", evt.Platform, evt.Type, elapsed)
+			}
+		}
+	}
+	return func(next Handler) Handler {
+		return func(evt types.Event) {
+			start := time.Now()
+			defer func() { report(evt, time.Since(start)) }()
+			next(evt)
+		}
+	}
+}
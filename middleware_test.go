@@ -0,0 +1,120 @@
+package whiskercat
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// trace returns middleware that records name before and after the rest of
+// the chain.
+func trace(name string, got *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(evt types.Event) {
+			*got = append(*got, name)
+			next(evt)
+			*got = append(*got, "/"+name)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var got []string
+	b := inline(WithMiddleware(trace("a", &got)))
+	b.Use(trace("b", &got), trace("c", &got))
+	b.OnEvent(func(types.Event) { got = append(got, "handler") })
+
+	b.dispatch(message("general", 1))
+
+	want := []string{"a", "b", "c", "handler", "/c", "/b", "/a"}
+	if !slices.Equal(got, want) {
+		t.Errorf("chain ran %v, want %v", got, want)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware Middleware
+		evt        types.Event
+		handled    bool
+	}{
+		{"Filter keeps", Filter(func(evt types.Event) bool { return evt.ChannelID == "general" }), message("general", 1), true},
+		{"Filter drops", Filter(func(evt types.Event) bool { return evt.ChannelID == "general" }), message("random", 1), false},
+		{"IgnoreBots keeps users", IgnoreBots(), message("general", 1), true},
+		{"IgnoreBots drops bots", IgnoreBots(), types.Event{Type: types.MessageCreate, Bot: true}, false},
+		{"IgnoreBots drops the bot itself", IgnoreBots(), types.Event{Type: types.MessageCreate, Bot: true, Self: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			b := inline(WithMiddleware(tt.middleware, trace("inner", &got)))
+			handled := false
+			b.OnEvent(func(types.Event) { handled = true })
+
+			b.dispatch(tt.evt)
+
+			if handled != tt.handled {
+				t.Errorf("handled = %v, want %v", handled, tt.handled)
+			}
+			// Dropped events do not reach later middleware either.
+			if reached := len(got) > 0; reached != tt.handled {
+				t.Errorf("inner middleware ran = %v, want %v", reached, tt.handled)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	b := inline(WithMiddleware(Recover()))
+	var handled []int
+	b.OnEvent(func(evt types.Event) {
+		if evt.Data.(int) == 1 {
+			panic("boom")
+		}
+		handled = append(handled, evt.Data.(int))
+	})
+
+	for n := 1; n <= 2; n++ {
+		b.dispatch(message("general", n))
+	}
+
+	if !slices.Equal(handled, []int{2}) {
+		t.Errorf("handled %v after a panic, want [2]", handled)
+	}
+	// The panicking event still counted as done.
+	drained := make(chan struct{})
+	go func() { b.inflight.Wait(); close(drained) }()
+	wait(t, drained, "in-flight events to drain")
+}
+
+func TestTiming(t *testing.T) {
+	var (
+		reported []types.Event
+		elapsed  time.Duration
+	)
+	b := inline(WithMiddleware(Recover(), Timing(func(evt types.Event, d time.Duration) {
+		reported = append(reported, evt)
+		elapsed = d
+	})))
+	b.OnEvent(func(types.Event) { time.Sleep(5 * time.Millisecond) })
+	b.OnEvent(func(evt types.Event) {
+		if evt.Data.(int) == 2 {
+			panic("boom")
+		}
+	})
+
+	b.dispatch(message("general", 1))
+	if len(reported) != 1 || reported[0].Data != 1 || elapsed < 5*time.Millisecond {
+		t.Errorf("reported %d events, last after %s; want event 1 after at least 5ms", len(reported), elapsed)
+	}
+
+	// A panicking handler is still timed.
+	b.dispatch(message("general", 2))
+	if len(reported) != 2 {
+		t.Errorf("reported %d events, want the panicking one too", len(reported))
+	}
+}
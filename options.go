@@ -0,0 +1,56 @@
+package whiskercat
+
+import (
+	"github.com/luvixsocial/whiskercat/link"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/state"
+)
+
+// Option configures a Bot created by New or NewWithPlatforms.
+type Option func(*Bot)
+
+// WithReconnectPolicy overrides DefaultReconnectPolicy.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(b *Bot) {
+		b.supervisor.policy = policy
+	}
+}
+
+// WithMiddleware adds middleware to the bot, as Use does.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(b *Bot) {
+		b.middleware = append(b.middleware, middleware...)
+	}
+}
+
+// WithDispatch overrides DefaultDispatchConfig.
+func WithDispatch(config DispatchConfig) Option {
+	return func(b *Bot) {
+		b.dispatchConfig = config
+	}
+}
+
+// WithState overrides state.DefaultConfig, choosing which entities the
+// bot's State caches and how many.
+func WithState(config state.Config) Option {
+	return func(b *Bot) {
+		b.stateConfig = config
+	}
+}
+
+// WithDirectoryCache bounds the user and member caches behind User and
+// Member on every platform that has them. By default each holds
+// cache.DefaultSize entries for cache.DefaultTTL.
+func WithDirectoryCache(config platform.CacheConfig) Option {
+	return func(b *Bot) {
+		b.directoryCache = config
+	}
+}
+
+// WithLinkStore keeps account links in store. By default they are kept in
+// memory and lost on restart; link.NewFileStore persists them.
+func WithLinkStore(store link.Store) Option {
+	return func(b *Bot) {
+		b.linkStore = store
+	}
+}
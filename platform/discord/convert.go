@@ -0,0 +1,200 @@
+package discord
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+func convertUser(user *discordgo.User) types.User {
+	if user == nil {
+		return types.User{}
+	}
+	return types.User{
+		ID:       user.ID,
+		Username: user.Username,
+		Avatar:   user.AvatarURL("128"),
+	}
+}
+
+// channelMention matches <#id> channel mentions in message content.
+var channelMention = regexp.MustCompile(`<#(\d+)>`)
+
+func convertMessage(m *discordgo.Message) types.MessageCallback {
+	msg := types.MessageCallback{
+		ID:        m.ID,
+		ChannelID: m.ChannelID,
+		ServerID:  m.GuildID,
+		Content:   m.Content,
+		Author:    convertUser(m.Author),
+		CreatedAt: m.Timestamp,
+		Mentions: types.Mentions{
+			Roles:    m.MentionRoles,
+			Channels: mentionedChannels(m.Content),
+		},
+		// Messages only lack a guild in DMs.
+		DM: m.GuildID == "",
+	}
+	if m.EditedTimestamp != nil {
+		msg.EditedAt = *m.EditedTimestamp
+	}
+	if m.Type == discordgo.MessageTypeReply && m.MessageReference != nil {
+		msg.ReplyTo = m.MessageReference.MessageID
+	}
+	for _, user := range m.Mentions {
+		msg.Mentions.Users = append(msg.Mentions.Users, user.ID)
+	}
+	return msg
+}
+
+func convertGuild(guild *discordgo.Guild) types.Server {
+	server := types.Server{
+		ID:      guild.ID,
+		Name:    guild.Name,
+		OwnerID: guild.OwnerID,
+	}
+	if guild.Icon != "" {
+		server.Icon = guild.IconURL("128")
+	}
+	// Channels listed in a guild do not repeat its ID.
+	for _, channel := range append(guild.Channels, guild.Threads...) {
+		c := convertChannel(channel)
+		c.ServerID = guild.ID
+		server.Channels = append(server.Channels, c)
+	}
+	for _, role := range guild.Roles {
+		server.Roles = append(server.Roles, convertRole(guild.ID, role))
+	}
+	return server
+}
+
+func convertChannel(channel *discordgo.Channel) types.Channel {
+	return types.Channel{
+		ID:       channel.ID,
+		ServerID: channel.GuildID,
+		ParentID: channel.ParentID,
+		Type:     channelType(channel.Type),
+		Name:     channel.Name,
+		Topic:    channel.Topic,
+		NSFW:     channel.NSFW,
+	}
+}
+
+func channelType(t discordgo.ChannelType) types.ChannelType {
+	switch t {
+	case discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildForum, discordgo.ChannelTypeGuildMedia:
+		return types.ChannelText
+	case discordgo.ChannelTypeGuildVoice, discordgo.ChannelTypeGuildStageVoice:
+		return types.ChannelVoice
+	case discordgo.ChannelTypeGuildCategory:
+		return types.ChannelCategory
+	case discordgo.ChannelTypeGuildNewsThread, discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread:
+		return types.ChannelThread
+	case discordgo.ChannelTypeDM:
+		return types.ChannelDM
+	case discordgo.ChannelTypeGroupDM:
+		return types.ChannelGroup
+	default:
+		return types.ChannelOther
+	}
+}
+
+func convertRole(guildID string, role *discordgo.Role) types.Role {
+	return types.Role{
+		ID:          role.ID,
+		ServerID:    guildID,
+		Name:        role.Name,
+		Color:       role.Color,
+		Position:    role.Position,
+		Hoist:       role.Hoist,
+		Permissions: role.Permissions,
+	}
+}
+
+// convertReaction normalizes a reaction. Discord only sends the reacting
+// member along with added reactions, so User starts out as just the ID.
+func convertReaction(r *discordgo.MessageReaction) types.ReactionCallback {
+	return types.ReactionCallback{
+		MessageID: r.MessageID,
+		ChannelID: r.ChannelID,
+		ServerID:  r.GuildID,
+		User:      types.User{ID: r.UserID},
+		Emoji: types.Emoji{
+			Name:     r.Emoji.Name,
+			ID:       r.Emoji.ID,
+			Animated: r.Emoji.Animated,
+		},
+	}
+}
+
+// mentionedChannels returns the unique channel IDs mentioned in content.
+func mentionedChannels(content string) []string {
+	var ids []string
+	for _, match := range channelMention.FindAllStringSubmatch(content, -1) {
+		if !slices.Contains(ids, match[1]) {
+			ids = append(ids, match[1])
+		}
+	}
+	return ids
+}
+
+func convertMember(member *discordgo.Member) types.MemberCallback {
+	return types.MemberCallback{
+		User:     convertUser(member.User),
+		ServerID: member.GuildID,
+		JoinedAt: member.JoinedAt,
+		Roles:    member.Roles,
+	}
+}
+
+func convertOptionsToMap(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]string {
+	result := make(map[string]string)
+	for _, opt := range options {
+		if opt != nil && opt.Value != nil {
+			result[opt.Name] = fmt.Sprintf("%v", opt.Value)
+		}
+	}
+	return result
+}
+
+func convertEmbed(embed *types.Embed) *discordgo.MessageEmbed {
+	if embed == nil {
+		return nil
+	}
+
+	em := &discordgo.MessageEmbed{
+		Title:       embed.Title,
+		Description: embed.Description,
+		Color:       embed.Color,
+	}
+
+	if embed.URL != nil {
+		em.URL = *embed.URL
+	}
+	if embed.IconURL != nil {
+		em.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: *embed.IconURL}
+	}
+	if embed.PhotoURL != nil {
+		em.Image = &discordgo.MessageEmbedImage{URL: *embed.PhotoURL}
+	}
+	if embed.Footer != nil {
+		em.Footer = &discordgo.MessageEmbedFooter{
+			Text:    embed.Footer.Text,
+			IconURL: embed.Footer.PhotoURL,
+		}
+	}
+
+	if embed.Fields != nil {
+		for _, f := range *embed.Fields {
+			em.Fields = append(em.Fields, &discordgo.MessageEmbedField{
+				Name:   f.Name,
+				Value:  f.Value,
+				Inline: f.Inline,
+			})
+		}
+	}
+	return em
+}
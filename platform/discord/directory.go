@@ -0,0 +1,77 @@
+package discord
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// memberKey identifies a membership in the member cache.
+type memberKey struct {
+	guildID, userID string
+}
+
+// User implements platform.Directory.
+func (a *Adapter) User(ctx context.Context, userID string) (types.User, error) {
+	return a.users.GetOrLoad(userID, func() (types.User, error) {
+		user, err := a.session.User(userID, discordgo.WithContext(ctx))
+		if err != nil {
+			return types.User{}, err
+		}
+		return convertUser(user), nil
+	})
+}
+
+// Member implements platform.Directory. discordgo's own state is consulted
+// before the REST API.
+func (a *Adapter) Member(ctx context.Context, guildID, userID string) (types.MemberCallback, error) {
+	return a.members.GetOrLoad(memberKey{guildID, userID}, func() (types.MemberCallback, error) {
+		member, err := a.session.State.Member(guildID, userID)
+		if err != nil {
+			if member, err = a.session.GuildMember(guildID, userID, discordgo.WithContext(ctx)); err != nil {
+				return types.MemberCallback{}, err
+			}
+		}
+		// Members fetched by ID do not repeat their guild. The state's copy
+		// is shared, so the field is set on a copy.
+		m := *member
+		m.GuildID = guildID
+		return convertMember(&m), nil
+	})
+}
+
+// CacheStats implements platform.Directory.
+func (a *Adapter) CacheStats() cache.Stats {
+	return a.users.Stats().Add(a.members.Stats())
+}
+
+// ConfigureCache implements platform.Directory.
+func (a *Adapter) ConfigureCache(config platform.CacheConfig) {
+	a.users = cache.New[string, types.User](config.Size, config.TTL)
+	a.members = cache.New[memberKey, types.MemberCallback](config.Size, config.TTL)
+}
+
+// rememberUser caches a user seen in a gateway event.
+func (a *Adapter) rememberUser(user *discordgo.User) {
+	if user != nil && user.ID != "" {
+		a.users.Set(user.ID, convertUser(user))
+	}
+}
+
+// rememberMember caches a member seen in a gateway event. Members attached
+// to messages lack their user, so it is passed separately.
+func (a *Adapter) rememberMember(guildID string, user *discordgo.User, member *discordgo.Member) {
+	if member == nil || user == nil || guildID == "" {
+		return
+	}
+	a.rememberUser(user)
+	a.members.Set(memberKey{guildID, user.ID}, types.MemberCallback{
+		User:     convertUser(user),
+		ServerID: guildID,
+		JoinedAt: member.JoinedAt,
+		Roles:    member.Roles,
+	})
+}
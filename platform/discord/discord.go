@@ -0,0 +1,225 @@
+// Package discord adapts discordgo to the platform.Platform interface.
+package discord
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Name is the value used in types.Event.Platform for Discord events.
+const Name = "Discord"
+
+func init() {
+	platform.Register(Name, func(config *types.AuthConfig) (platform.Platform, error) {
+		if !config.Discord.Enabled() {
+			return nil, nil
+		}
+		return New(*config.Discord)
+	})
+}
+
+// Adapter implements platform.Platform on top of a discordgo session.
+type Adapter struct {
+	session *discordgo.Session
+
+	ready platform.ReadySignal
+
+	mu   sync.RWMutex
+	sink func(types.Event)
+
+	users   *cache.Cache[string, types.User]
+	members *cache.Cache[memberKey, types.MemberCallback]
+
+	webhookLookupMu sync.Mutex // serializes finding and creating webhooks
+	webhooksMu      sync.Mutex
+	webhooks        map[string]*discordgo.Webhook // By channel; nil where none can be used
+	webhookFailures map[string]time.Time          // By channel; when to try finding the webhook again
+}
+
+// New creates a Discord adapter and registers its gateway handlers.
+func New(config types.DiscordConfig) (*Adapter, error) {
+	session, err := discordgo.New("Bot " + config.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Discord client: %w", err)
+	}
+
+	// Reconnects are driven by whoever owns the adapter (the bot's
+	// supervisor), so discordgo must not race it with its own loop.
+	session.ShouldReconnectOnError = false
+
+	a := &Adapter{
+		session:         session,
+		webhooks:        make(map[string]*discordgo.Webhook),
+		webhookFailures: make(map[string]time.Time),
+	}
+	a.ConfigureCache(platform.CacheConfig{})
+	a.registerEvents()
+	return a, nil
+}
+
+// Session returns the underlying discordgo session.
+func (a *Adapter) Session() *discordgo.Session { return a.session }
+
+// Name implements platform.Platform.
+func (a *Adapter) Name() string { return Name }
+
+// Connect implements platform.Platform.
+func (a *Adapter) Connect(_ context.Context) error {
+	a.ready.Reset()
+	return a.session.Open()
+}
+
+// Disconnect implements platform.Platform.
+func (a *Adapter) Disconnect(_ context.Context) error {
+	return a.session.Close()
+}
+
+// Ready implements platform.Platform.
+func (a *Adapter) Ready() <-chan struct{} { return a.ready.Done() }
+
+// HandleEvents implements platform.Platform.
+func (a *Adapter) HandleEvents(sink func(types.Event)) {
+	a.mu.Lock()
+	a.sink = sink
+	a.mu.Unlock()
+}
+
+func (a *Adapter) emit(evt types.Event) {
+	a.mu.RLock()
+	sink := a.sink
+	a.mu.RUnlock()
+
+	if sink != nil {
+		sink(evt)
+	}
+}
+
+// Send implements platform.Platform.
+// Masqueraded messages are sent through a webhook, which needs the Manage
+// Webhooks permission; in threads, DMs and channels where the webhook
+// cannot be created the bot sends them itself.
+func (a *Adapter) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	send := &discordgo.MessageSend{Content: msg.Content}
+	if msg.Masquerade != nil {
+		if wh := a.webhook(channelID); wh != nil {
+			return a.sendWebhook(wh, msg)
+		}
+		send.Content = masqueradeContent(msg)
+		send.AllowedMentions = &discordgo.MessageAllowedMentions{}
+	}
+	if msg.ReplyTo != "" {
+		send.Reference = &discordgo.MessageReference{MessageID: msg.ReplyTo, ChannelID: channelID}
+	}
+	if msg.Embed != nil {
+		send.Embeds = []*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+	}
+	return sent(a.session.ChannelMessageSendComplex(channelID, send))
+}
+
+// Edit implements platform.Platform. Messages sent with a Masquerade must
+// be edited with one too, and keep their original name and avatar.
+func (a *Adapter) Edit(channelID, messageID string, msg types.MessageSend) (*types.SentMessage, error) {
+	content := msg.Content
+	if msg.Masquerade != nil {
+		if wh := a.webhook(channelID); wh != nil {
+			return a.editWebhook(wh, messageID, msg)
+		}
+		content = masqueradeContent(msg)
+	}
+	edit := &discordgo.MessageEdit{ID: messageID, Channel: channelID, Content: &content}
+	if msg.Embed != nil {
+		edit.Embeds = &[]*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+	}
+	return sent(a.session.ChannelMessageEditComplex(edit))
+}
+
+// Delete implements platform.Platform.
+func (a *Adapter) Delete(channelID, messageID string) error {
+	return a.session.ChannelMessageDelete(channelID, messageID)
+}
+
+// React implements platform.Platform.
+func (a *Adapter) React(channelID, messageID, emoji string) error {
+	return a.session.MessageReactionAdd(channelID, messageID, emoji)
+}
+
+// SetPresence implements platform.Platform.
+func (a *Adapter) SetPresence(status platform.Status) error {
+	update := discordgo.UpdateStatusData{
+		Activities: []*discordgo.Activity{
+			{
+				Name: status.Name,
+				Type: discordgo.ActivityType(status.Activity),
+			},
+		},
+	}
+
+	if status.Raw != nil {
+		update.Status = *status.Raw
+	}
+
+	return a.session.UpdateStatusComplex(update)
+}
+
+// Respond implements platform.Platform.
+func (a *Adapter) Respond(e types.Event, msg types.MessageSend, edit *string) (*types.SentMessage, error) {
+	switch ctx := e.Context.(type) {
+	case *discordgo.MessageCreate:
+		if edit != nil {
+			return a.Edit(ctx.ChannelID, *edit, msg)
+		}
+		send := &discordgo.MessageSend{
+			Content:   msg.Content,
+			Reference: &discordgo.MessageReference{MessageID: ctx.ID, ChannelID: ctx.ChannelID, GuildID: ctx.GuildID},
+		}
+		if msg.Embed != nil {
+			send.Embeds = []*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+		}
+		return sent(a.session.ChannelMessageSendComplex(ctx.ChannelID, send))
+
+	case *discordgo.InteractionCreate:
+		if edit != nil {
+			ed := &discordgo.WebhookEdit{Content: &msg.Content}
+			if msg.Embed != nil {
+				ed.Embeds = &[]*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+			}
+			return sent(a.session.InteractionResponseEdit(ctx.Interaction, ed))
+		}
+		r := &discordgo.InteractionResponseData{Content: msg.Content}
+		if msg.Embed != nil {
+			r.Embeds = []*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+		}
+		if msg.Ephemeral {
+			r.Flags = discordgo.MessageFlagsEphemeral
+		}
+		err := a.session.InteractionRespond(ctx.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: r})
+		if err != nil {
+			return nil, err
+		}
+		// Interaction responses have no message ID until fetched; "@original"
+		// lets a later edit target the response.
+		return &types.SentMessage{ID: "@original", ChannelID: ctx.ChannelID, Platform: Name, Raw: ctx.Interaction}, nil
+	}
+
+	if e.ChannelID == "" {
+		return nil, fmt.Errorf("unsupported Discord context %T", e.Context)
+	}
+	if edit != nil {
+		return a.Edit(e.ChannelID, *edit, msg)
+	}
+	return a.Send(e.ChannelID, msg)
+}
+
+func sent(m *discordgo.Message, err error) (*types.SentMessage, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &types.SentMessage{ID: m.ID, ChannelID: m.ChannelID, Platform: Name, Raw: m}, nil
+}
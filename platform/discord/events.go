@@ -0,0 +1,209 @@
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// registerEvents wires discordgo handlers that normalize gateway events and
+// forward them to the installed sink.
+func (a *Adapter) registerEvents() {
+	event := func(eventType types.EventType, context any, session *discordgo.Session, bot bool, channelID, serverID string, data any) types.Event {
+		return types.Event{
+			Name:      string(eventType),
+			Type:      eventType,
+			Platform:  Name,
+			Bot:       bot,
+			Context:   context,
+			Session:   session,
+			Data:      data,
+			ChannelID: channelID,
+			ServerID:  serverID,
+		}
+	}
+	emit := func(eventType types.EventType, context any, session *discordgo.Session, bot bool, channelID, serverID string, data any) {
+		a.emit(event(eventType, context, session, bot, channelID, serverID, data))
+	}
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.Ready) {
+		a.ready.Fire()
+		emit(types.EventConnected, e, s, false, "", "", nil)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.Resumed) {
+		emit(types.EventResumed, e, s, false, "", "", nil)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.Disconnect) {
+		emit(types.EventDisconnected, e, s, false, "", "", nil)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageCreate) {
+		a.rememberUser(e.Author)
+		a.rememberMember(e.GuildID, e.Author, e.Member)
+		evt := event(types.MessageCreate, e, s, isBot(e.Author), e.ChannelID, e.GuildID, convertMessage(e.Message))
+		evt.Self = a.isSelf(e.Author, e.WebhookID)
+		a.emit(evt)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageUpdate) {
+		evt := event(types.MessageUpdate, e, s, isBot(e.Author), e.ChannelID, e.GuildID, convertMessage(e.Message))
+		evt.Self = a.isSelf(e.Author, e.WebhookID)
+		a.emit(evt)
+	})
+
+	// Deleted messages are only described in full when discordgo's state
+	// still had them.
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageDelete) {
+		deleted := e.Message
+		if e.BeforeDelete != nil {
+			deleted = e.BeforeDelete
+		}
+		emit(types.MessageDelete, e, s, false, e.ChannelID, e.GuildID, convertMessage(deleted))
+	})
+
+	// Bulk deletions are reported message by message, as if each had been
+	// deleted on its own.
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageDeleteBulk) {
+		for _, id := range e.Messages {
+			deleted := &discordgo.MessageDelete{Message: &discordgo.Message{ID: id, ChannelID: e.ChannelID, GuildID: e.GuildID}}
+			emit(types.MessageDelete, deleted, s, false, e.ChannelID, e.GuildID, convertMessage(deleted.Message))
+		}
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageReactionAdd) {
+		reaction := convertReaction(e.MessageReaction)
+		if e.Member != nil && e.Member.User != nil {
+			a.rememberMember(e.GuildID, e.Member.User, e.Member)
+			reaction.User = convertUser(e.Member.User)
+		}
+		evt := event(types.ReactionAdd, e, s, e.Member != nil && isBot(e.Member.User), e.ChannelID, e.GuildID, reaction)
+		evt.Self = a.isSelf(&discordgo.User{ID: e.UserID}, "")
+		a.emit(evt)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.MessageReactionRemove) {
+		evt := event(types.ReactionRemove, e, s, false, e.ChannelID, e.GuildID, convertReaction(e.MessageReaction))
+		evt.Self = a.isSelf(&discordgo.User{ID: e.UserID}, "")
+		a.emit(evt)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.InteractionCreate) {
+		if e.Type != discordgo.InteractionApplicationCommand {
+			return
+		}
+		data := e.ApplicationCommandData()
+		emit(types.InteractionCreate, e, s, false, e.ChannelID, e.GuildID, types.InteractionCallback{
+			Name:   data.Name,
+			Fields: convertOptionsToMap(data.Options),
+			Data:   e,
+			Author: convertUser(interactionUser(e)),
+			DM:     e.GuildID == "",
+		})
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.TypingStart) {
+		emit(types.EventTypingStart, e, s, false, e.ChannelID, e.GuildID, types.User{ID: e.UserID})
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.VoiceStateUpdate) {
+		emit(types.EventVoiceStateUpdate, e, s, false, e.ChannelID, e.GuildID, nil)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.PresenceUpdate) {
+		emit(types.EventPresenceUpdate, e, s, false, "", e.GuildID, nil)
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.ChannelCreate) {
+		emit(types.EventChannelCreate, e, s, false, e.ID, e.GuildID, convertChannel(e.Channel))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.ChannelUpdate) {
+		emit(types.EventChannelUpdate, e, s, false, e.ID, e.GuildID, convertChannel(e.Channel))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.ChannelDelete) {
+		emit(types.EventChannelDelete, e, s, false, e.ID, e.GuildID, convertChannel(e.Channel))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.ThreadCreate) {
+		emit(types.EventThreadCreate, e, s, false, e.ID, e.GuildID, convertChannel(e.Channel))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.ThreadUpdate) {
+		emit(types.EventThreadUpdate, e, s, false, e.ID, e.GuildID, convertChannel(e.Channel))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.ThreadDelete) {
+		emit(types.EventThreadDelete, e, s, false, e.ID, e.GuildID, convertChannel(e.Channel))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.UserUpdate) {
+		a.rememberUser(e.User)
+		emit(types.EventUserUpdate, e, s, isBot(e.User), "", "", convertUser(e.User))
+	})
+
+	// GuildCreate is also sent for every guild while connecting and when a
+	// guild recovers from an outage.
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildCreate) {
+		emit(types.EventServerCreate, e, s, false, "", e.ID, convertGuild(e.Guild))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildUpdate) {
+		emit(types.EventServerUpdate, e, s, false, "", e.ID, convertGuild(e.Guild))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildDelete) {
+		// Unavailable guilds are in an outage; the bot is still a member.
+		if e.Unavailable {
+			return
+		}
+		guild := e.Guild
+		if e.BeforeDelete != nil {
+			guild = e.BeforeDelete
+		}
+		emit(types.EventServerDelete, e, s, false, "", e.ID, convertGuild(guild))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildRoleCreate) {
+		emit(types.EventRoleCreate, e, s, false, "", e.GuildID, convertRole(e.GuildID, e.Role))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildRoleUpdate) {
+		emit(types.EventRoleUpdate, e, s, false, "", e.GuildID, convertRole(e.GuildID, e.Role))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildRoleDelete) {
+		emit(types.EventRoleDelete, e, s, false, "", e.GuildID, types.Role{ID: e.RoleID, ServerID: e.GuildID})
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildMemberAdd) {
+		a.rememberMember(e.GuildID, e.User, e.Member)
+		emit(types.EventMemberJoin, e, s, isBot(e.User), "", e.GuildID, convertMember(e.Member))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildMemberUpdate) {
+		a.rememberMember(e.GuildID, e.User, e.Member)
+		emit(types.EventMemberUpdate, e, s, isBot(e.User), "", e.GuildID, convertMember(e.Member))
+	})
+
+	a.session.AddHandler(func(s *discordgo.Session, e *discordgo.GuildMemberRemove) {
+		if e.User != nil {
+			a.members.Delete(memberKey{e.GuildID, e.User.ID})
+		}
+		emit(types.EventMemberLeave, e, s, isBot(e.User), "", e.GuildID, convertMember(e.Member))
+	})
+}
+
+func isBot(user *discordgo.User) bool {
+	return user != nil && user.Bot
+}
+
+// interactionUser returns the invoker of an interaction, which lives on
+// Member in guilds and on User in DMs.
+func interactionUser(e *discordgo.InteractionCreate) *discordgo.User {
+	if e.Member != nil && e.Member.User != nil {
+		return e.Member.User
+	}
+	return e.User
+}
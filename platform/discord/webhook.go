@@ -0,0 +1,169 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// webhookName names the webhooks created for masqueraded messages.
+const webhookName = "WhiskerCat"
+
+// webhookRetry is how long a channel whose webhook could not be found or
+// created, usually for lack of the Manage Webhooks permission, is not
+// tried again.
+const webhookRetry = 10 * time.Minute
+
+// webhook returns the bot's webhook for a channel, creating it if needed.
+// It returns nil for channels without webhooks, such as threads and DMs,
+// and for channels where the webhook is unavailable, in which case the
+// masqueraded message is sent by the bot itself.
+func (a *Adapter) webhook(channelID string) *discordgo.Webhook {
+	if wh, known := a.knownWebhook(channelID); known {
+		return wh
+	}
+
+	// Lookups make REST calls, so they hold a lock of their own rather than
+	// webhooksMu, which gateway events wait on in ownsWebhook.
+	a.webhookLookupMu.Lock()
+	defer a.webhookLookupMu.Unlock()
+	if wh, known := a.knownWebhook(channelID); known {
+		return wh
+	}
+
+	wh, err := a.findWebhook(channelID)
+
+	a.webhooksMu.Lock()
+	defer a.webhooksMu.Unlock()
+	if err != nil {
+		log.Printf("Discord webhook unavailable in %s, sending masqueraded messages as the bot: %v\n", channelID, err)
+		a.webhookFailures[channelID] = time.Now().Add(webhookRetry)
+		return nil
+	}
+	delete(a.webhookFailures, channelID)
+	a.webhooks[channelID] = wh
+	return wh
+}
+
+// knownWebhook returns a channel's webhook if it was looked up before. It
+// reports a channel whose lookup failed recently as known, without webhook.
+func (a *Adapter) knownWebhook(channelID string) (wh *discordgo.Webhook, known bool) {
+	a.webhooksMu.Lock()
+	defer a.webhooksMu.Unlock()
+
+	if wh, ok := a.webhooks[channelID]; ok {
+		return wh, true
+	}
+	return nil, time.Now().Before(a.webhookFailures[channelID])
+}
+
+// findWebhook looks up or creates the bot's webhook for a channel.
+func (a *Adapter) findWebhook(channelID string) (*discordgo.Webhook, error) {
+	channel, err := a.session.State.Channel(channelID)
+	if err != nil {
+		if channel, err = a.session.Channel(channelID); err != nil {
+			return nil, err
+		}
+	}
+	if channel.Type != discordgo.ChannelTypeGuildText && channel.Type != discordgo.ChannelTypeGuildNews {
+		return nil, nil
+	}
+
+	hooks, err := a.session.ChannelWebhooks(channelID)
+	if err != nil {
+		return nil, err
+	}
+	var self string
+	if a.session.State.User != nil {
+		self = a.session.State.User.ID
+	}
+	for _, wh := range hooks {
+		if wh.Type == discordgo.WebhookTypeIncoming && wh.Token != "" && wh.User != nil && wh.User.ID == self {
+			return wh, nil
+		}
+	}
+	return a.session.WebhookCreate(channelID, webhookName, "")
+}
+
+// forgetWebhook drops a channel's webhook after Discord reports it gone, so
+// the next message creates a new one.
+func (a *Adapter) forgetWebhook(channelID string, err error) {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownWebhook {
+		a.webhooksMu.Lock()
+		delete(a.webhooks, channelID)
+		a.webhooksMu.Unlock()
+	}
+}
+
+// ownsWebhook reports whether id is one of the bot's webhooks.
+func (a *Adapter) ownsWebhook(id string) bool {
+	a.webhooksMu.Lock()
+	defer a.webhooksMu.Unlock()
+	for _, wh := range a.webhooks {
+		if wh != nil && wh.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelf reports whether a message was sent by the bot, directly or
+// through one of its webhooks.
+func (a *Adapter) isSelf(author *discordgo.User, webhookID string) bool {
+	if webhookID != "" {
+		return a.ownsWebhook(webhookID)
+	}
+	return author != nil && a.session.State.User != nil && author.ID == a.session.State.User.ID
+}
+
+func (a *Adapter) sendWebhook(wh *discordgo.Webhook, msg types.MessageSend) (*types.SentMessage, error) {
+	params := &discordgo.WebhookParams{
+		Content:   a.webhookContent(wh, msg),
+		Username:  msg.Masquerade.Name,
+		AvatarURL: msg.Masquerade.Avatar,
+		// Relayed text must not ping anyone on this side.
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if msg.Embed != nil {
+		params.Embeds = []*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+	}
+	m, err := a.session.WebhookExecute(wh.ID, wh.Token, true, params)
+	if err != nil {
+		a.forgetWebhook(wh.ChannelID, err)
+	}
+	return sent(m, err)
+}
+
+func (a *Adapter) editWebhook(wh *discordgo.Webhook, messageID string, msg types.MessageSend) (*types.SentMessage, error) {
+	content := a.webhookContent(wh, msg)
+	edit := &discordgo.WebhookEdit{Content: &content}
+	if msg.Embed != nil {
+		edit.Embeds = &[]*discordgo.MessageEmbed{convertEmbed(msg.Embed)}
+	}
+	m, err := a.session.WebhookMessageEdit(wh.ID, wh.Token, messageID, edit)
+	if err != nil {
+		a.forgetWebhook(wh.ChannelID, err)
+	}
+	return sent(m, err)
+}
+
+// webhookContent returns a webhook message's content. Webhooks cannot
+// reply, so replies start with a link to the message instead, which
+// Discord renders as a jump link.
+func (a *Adapter) webhookContent(wh *discordgo.Webhook, msg types.MessageSend) string {
+	if msg.ReplyTo == "" {
+		return msg.Content
+	}
+	return fmt.Sprintf("-# ↪ https://discord.com/channels/%s/%s/%s\n%s", wh.GuildID, wh.ChannelID, msg.ReplyTo, msg.Content)
+}
+
+// masqueradeContent returns the content of a masqueraded message sent by
+// the bot itself, where no webhook can be used: the name is shown first.
+func masqueradeContent(msg types.MessageSend) string {
+	return fmt.Sprintf("**%s**: %s", msg.Masquerade.Name, msg.Content)
+}
@@ -0,0 +1,130 @@
+package irc
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// floodBurst is how many queued lines may be sent back to back before
+	// the flood delay applies.
+	floodBurst = 4
+
+	// pingInterval is how often the connection is probed, and readTimeout
+	// how long the server may stay silent before it is considered gone.
+	pingInterval = 2 * time.Minute
+	readTimeout  = 5 * time.Minute
+)
+
+// conn is a single connection to the server. Every reconnect gets a new one.
+type conn struct {
+	net.Conn
+
+	writeMu sync.Mutex
+	queue   chan string
+	done    chan struct{}
+	once    sync.Once
+
+	registered atomic.Bool // RPL_WELCOME received
+	closing    atomic.Bool // closed by Disconnect rather than the server
+}
+
+func newConn(c net.Conn) *conn {
+	return &conn{
+		Conn:  c,
+		queue: make(chan string, 256),
+		done:  make(chan struct{}),
+	}
+}
+
+// writeNow sends a line immediately, bypassing the flood queue. It is used
+// for registration and keepalive traffic.
+func (c *conn) writeNow(line string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.SetWriteDeadline(time.Now().Add(30 * time.Second))
+	_, err := c.Write([]byte(sanitize(line) + "\r\n"))
+	return err
+}
+
+// enqueue sends a line through the flood queue.
+func (c *conn) enqueue(line string) error {
+	select {
+	case c.queue <- line:
+		return nil
+	case <-c.done:
+		return net.ErrClosed
+	}
+}
+
+// writeLoop drains the queue, letting floodBurst lines through at once and
+// then one line per delay, and pings the server periodically.
+func (c *conn) writeLoop(delay time.Duration) {
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	var next time.Time
+	for {
+		select {
+		case line := <-c.queue:
+			now := time.Now()
+			if next.Before(now) {
+				next = now
+			}
+			next = next.Add(delay)
+			if wait := next.Sub(now) - floodBurst*delay; wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-c.done:
+					return
+				}
+			}
+			if err := c.writeNow(line); err != nil {
+				c.close()
+				return
+			}
+
+		case <-ping.C:
+			if err := c.writeNow("PING :whiskercat"); err != nil {
+				c.close()
+				return
+			}
+
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readLoop calls handle for every line until the connection fails.
+func (c *conn) readLoop(handle func(*Message)) error {
+	reader := bufio.NewReaderSize(c, 4096)
+	for {
+		_ = c.SetReadDeadline(time.Now().Add(readTimeout))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			handle(parseMessage(line))
+		}
+	}
+}
+
+func (c *conn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.Conn.Close()
+	})
+}
+
+// sanitize keeps a line from smuggling extra commands.
+func sanitize(line string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(line)
+}
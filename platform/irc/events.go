@@ -0,0 +1,154 @@
+package irc
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// handle processes one line from the server: registration and keepalive
+// are answered here, and PRIVMSG, JOIN, PART, KICK and QUIT are normalized
+// into events.
+func (a *Adapter) handle(c *conn, m *Message, registered chan<- error) {
+	emit := func(eventType types.EventType, channelID string, data any) {
+		a.emit(types.Event{
+			Name:      string(eventType),
+			Type:      eventType,
+			Platform:  Name,
+			Bot:       a.isSelf(m.Nick()),
+			Context:   m,
+			Session:   a,
+			Data:      data,
+			ChannelID: channelID,
+		})
+	}
+	// registered only takes the first outcome; later ones must not block
+	// the read loop.
+	report := func(err error) {
+		select {
+		case registered <- err:
+		default:
+		}
+	}
+	fail := func(err error) {
+		report(err)
+		c.close()
+	}
+
+	switch m.Command {
+	case "PING":
+		_ = c.writeNow("PONG :" + m.Param(0))
+
+	// SASL PLAIN, requested by Connect with CAP REQ.
+	case "CAP":
+		switch m.Param(1) {
+		case "ACK":
+			_ = c.writeNow("AUTHENTICATE PLAIN")
+		case "NAK":
+			fail(errors.New("server does not support SASL"))
+		}
+	case "AUTHENTICATE":
+		if m.Param(0) == "+" {
+			user, password := a.config.SASLUser, a.config.SASLPassword
+			_ = c.writeNow("AUTHENTICATE " + base64.StdEncoding.EncodeToString([]byte(user+"\x00"+user+"\x00"+password)))
+		}
+	case "903": // RPL_SASLSUCCESS
+		_ = c.writeNow("CAP END")
+	case "902", "904", "905", "906", "908": // SASL failures
+		fail(errors.New("SASL authentication failed: " + m.Param(len(m.Params)-1)))
+
+	case "433": // ERR_NICKNAMEINUSE
+		if !c.registered.Load() {
+			nick := a.Nick() + "_"
+			a.setNick(nick)
+			_ = c.writeNow("NICK " + nick)
+		}
+	case "464", "465": // ERR_PASSWDMISMATCH, ERR_YOUREBANNEDCREEP
+		fail(errors.New(m.Param(len(m.Params) - 1)))
+	case "ERROR":
+		if !c.registered.Load() {
+			fail(errors.New(m.Param(0)))
+		}
+
+	case "001": // RPL_WELCOME
+		a.setNick(m.Param(0))
+		c.registered.Store(true)
+		a.mu.Lock()
+		a.conn = c
+		a.mu.Unlock()
+
+		if a.config.NickServPassword != "" {
+			_ = c.enqueue("PRIVMSG NickServ :IDENTIFY " + a.config.Nick + " " + a.config.NickServPassword)
+		}
+		a.joinAll(c)
+
+		report(nil)
+		a.ready.Fire()
+		a.emitState(types.EventConnected)
+
+	case "NICK":
+		if a.isSelf(m.Nick()) {
+			a.setNick(m.Param(0))
+		}
+
+	case "PRIVMSG":
+		target, text := m.Param(0), m.Param(1)
+		if strings.HasPrefix(text, "\x01") {
+			// Only /me actions are passed on; other CTCP queries are ignored.
+			action, ok := strings.CutPrefix(strings.Trim(text, "\x01"), "ACTION ")
+			if !ok {
+				return
+			}
+			text = action
+		}
+		channelID := target
+		if !isChannel(target) {
+			// Private messages are answered in a query with the sender.
+			channelID = m.Nick()
+		}
+		// IRC messages have no IDs, so only the channel and time are known.
+		emit(types.MessageCreate, channelID, types.MessageCallback{
+			ChannelID: channelID,
+			Content:   text,
+			Author:    convertUser(m),
+			CreatedAt: time.Now(),
+			DM:        !isChannel(target),
+		})
+
+	case "JOIN":
+		emit(types.EventMemberJoin, m.Param(0), types.MemberCallback{User: convertUser(m), JoinedAt: time.Now()})
+
+	case "PART":
+		emit(types.EventMemberLeave, m.Param(0), types.MemberCallback{User: convertUser(m)})
+
+	case "KICK":
+		channel, nick := m.Param(0), m.Param(1)
+		emit(types.EventMemberLeave, channel, types.MemberCallback{User: types.User{ID: nick, Username: nick}})
+		if a.isSelf(nick) && a.wantsChannel(channel) {
+			go func() {
+				select {
+				case <-time.After(rejoinDelay):
+					if err := c.enqueue("JOIN " + channel); err != nil {
+						log.Printf("IRC rejoin of %s failed: %v\n", channel, err)
+					}
+				case <-c.done:
+				}
+			}()
+		}
+
+	case "QUIT":
+		// QUIT is not tied to a channel, so ChannelID is left empty.
+		emit(types.EventMemberLeave, "", types.MemberCallback{User: convertUser(m)})
+	}
+}
+
+// convertUser builds a user from a message prefix. IRC has no stable user
+// IDs, so the nickname is used as both ID and username.
+func convertUser(m *Message) types.User {
+	nick := m.Nick()
+	return types.User{ID: nick, Username: nick}
+}
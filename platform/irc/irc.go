@@ -0,0 +1,364 @@
+// Package irc adapts an IRC client connection to the platform.Platform
+// interface. Channels and private queries are treated as channels.
+package irc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Name is the value used in types.Event.Platform for IRC events.
+const Name = "IRC"
+
+// rejoinDelay is how long the adapter waits before rejoining after a kick.
+var rejoinDelay = 5 * time.Second
+
+func init() {
+	platform.Register(Name, func(config *types.AuthConfig) (platform.Platform, error) {
+		if !config.IRC.Enabled() {
+			return nil, nil
+		}
+		return New(*config.IRC)
+	})
+}
+
+// Adapter implements platform.Platform on top of an IRC connection.
+type Adapter struct {
+	config types.IRCConfig
+
+	ready platform.ReadySignal
+
+	connMu sync.Mutex // serializes Connect and Disconnect
+
+	mu       sync.RWMutex
+	conn     *conn // set once the server has welcomed the bot
+	sink     func(types.Event)
+	nick     string
+	channels map[string]string // lowercased name -> name, joined on every connect
+}
+
+// New creates an IRC adapter. Nothing is dialed until Connect.
+func New(config types.IRCConfig) (*Adapter, error) {
+	if config.Server == "" {
+		return nil, errors.New("no server provided")
+	}
+	if config.Nick == "" {
+		return nil, errors.New("no nick provided")
+	}
+	if config.Username == "" {
+		config.Username = config.Nick
+	}
+	if config.RealName == "" {
+		config.RealName = config.Nick
+	}
+	if config.FloodDelay <= 0 {
+		config.FloodDelay = time.Second
+	}
+
+	a := &Adapter{
+		config:   config,
+		nick:     config.Nick,
+		channels: make(map[string]string, len(config.Channels)),
+	}
+	for _, channel := range config.Channels {
+		a.channels[strings.ToLower(channel)] = channel
+	}
+	return a, nil
+}
+
+// Name implements platform.Platform.
+func (a *Adapter) Name() string { return Name }
+
+// Nick returns the bot's current nickname.
+func (a *Adapter) Nick() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.nick
+}
+
+func (a *Adapter) setNick(nick string) {
+	a.mu.Lock()
+	a.nick = nick
+	a.mu.Unlock()
+}
+
+func (a *Adapter) isSelf(nick string) bool {
+	return strings.EqualFold(nick, a.Nick())
+}
+
+// Connect implements platform.Platform. It dials the server, registers
+// (authenticating with SASL when configured) and returns once the server
+// has welcomed the bot. Channels are joined right after.
+func (a *Adapter) Connect(ctx context.Context) error {
+	a.connMu.Lock()
+	defer a.connMu.Unlock()
+
+	if _, err := a.current(); err == nil {
+		return errors.New("already connected")
+	}
+
+	netConn, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+	c := newConn(netConn)
+
+	a.ready.Reset()
+	a.setNick(a.config.Nick)
+	registered := make(chan error, 1)
+	go a.run(c, registered)
+	go c.writeLoop(a.config.FloodDelay)
+
+	var lines []string
+	if a.config.SASLUser != "" {
+		lines = append(lines, "CAP REQ :sasl")
+	}
+	if a.config.Password != "" {
+		lines = append(lines, "PASS "+a.config.Password)
+	}
+	lines = append(lines,
+		"NICK "+a.config.Nick,
+		"USER "+a.config.Username+" 0 * :"+a.config.RealName,
+	)
+	for _, line := range lines {
+		if err := c.writeNow(line); err != nil {
+			c.close()
+			return err
+		}
+	}
+
+	select {
+	case err = <-registered:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		c.closing.Store(true)
+		c.close()
+		return err
+	}
+	return nil
+}
+
+func (a *Adapter) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: time.Minute}
+	if !a.config.TLS {
+		return dialer.DialContext(ctx, "tcp", a.config.Server)
+	}
+
+	host, _, err := net.SplitHostPort(a.config.Server)
+	if err != nil {
+		return nil, err
+	}
+	tlsDialer := &tls.Dialer{
+		NetDialer: dialer,
+		Config: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: a.config.InsecureSkipVerify,
+		},
+	}
+	return tlsDialer.DialContext(ctx, "tcp", a.config.Server)
+}
+
+// run reads from c until it fails, then lets the supervisor know unless
+// the bot disconnected on purpose.
+func (a *Adapter) run(c *conn, registered chan<- error) {
+	err := c.readLoop(func(m *Message) { a.handle(c, m, registered) })
+	c.close()
+
+	if !c.registered.Load() {
+		select {
+		case registered <- fmt.Errorf("connection closed during registration: %w", err):
+		default:
+		}
+		return
+	}
+
+	a.mu.Lock()
+	if a.conn == c {
+		a.conn = nil
+	}
+	a.mu.Unlock()
+
+	if !c.closing.Load() {
+		a.emitState(types.EventDisconnected)
+	}
+}
+
+// Disconnect implements platform.Platform.
+func (a *Adapter) Disconnect(_ context.Context) error {
+	a.connMu.Lock()
+	defer a.connMu.Unlock()
+
+	a.mu.Lock()
+	c := a.conn
+	a.conn = nil
+	a.mu.Unlock()
+
+	if c == nil {
+		return nil
+	}
+	c.closing.Store(true)
+	_ = c.writeNow("QUIT :Goodbye")
+	c.close()
+	a.emitState(types.EventDisconnected)
+	return nil
+}
+
+func (a *Adapter) current() (*conn, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.conn == nil {
+		return nil, errors.New("irc: not connected")
+	}
+	return a.conn, nil
+}
+
+// Ready implements platform.Platform.
+func (a *Adapter) Ready() <-chan struct{} { return a.ready.Done() }
+
+// HandleEvents implements platform.Platform.
+func (a *Adapter) HandleEvents(sink func(types.Event)) {
+	a.mu.Lock()
+	a.sink = sink
+	a.mu.Unlock()
+}
+
+func (a *Adapter) emit(evt types.Event) {
+	a.mu.RLock()
+	sink := a.sink
+	a.mu.RUnlock()
+
+	if sink != nil {
+		sink(evt)
+	}
+}
+
+func (a *Adapter) emitState(eventType types.EventType) {
+	a.emit(types.Event{
+		Name:     string(eventType),
+		Type:     eventType,
+		Platform: Name,
+		Session:  a,
+	})
+}
+
+// Join joins channel now (if connected) and after every reconnect.
+func (a *Adapter) Join(channel string) error {
+	a.mu.Lock()
+	a.channels[strings.ToLower(channel)] = channel
+	a.mu.Unlock()
+
+	c, err := a.current()
+	if err != nil {
+		// Joined once connected.
+		return nil
+	}
+	return c.enqueue("JOIN " + channel)
+}
+
+// Part leaves channel and stops rejoining it.
+func (a *Adapter) Part(channel, reason string) error {
+	a.mu.Lock()
+	delete(a.channels, strings.ToLower(channel))
+	a.mu.Unlock()
+
+	c, err := a.current()
+	if err != nil {
+		return err
+	}
+	return c.enqueue("PART " + channel + " :" + reason)
+}
+
+func (a *Adapter) wantsChannel(channel string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.channels[strings.ToLower(channel)]
+	return ok
+}
+
+func (a *Adapter) joinAll(c *conn) {
+	a.mu.RLock()
+	channels := make([]string, 0, len(a.channels))
+	for _, channel := range a.channels {
+		channels = append(channels, channel)
+	}
+	a.mu.RUnlock()
+
+	for _, channel := range channels {
+		_ = c.enqueue("JOIN " + channel)
+	}
+}
+
+// Send implements platform.Platform. channelID is a channel name or, for
+// private messages, a nickname. The message is sent as one PRIVMSG per
+// line, paced by the flood delay. IRC messages have no IDs, so the returned
+// SentMessage has an empty ID.
+func (a *Adapter) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	c, err := a.current()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := renderLines(msg)
+	for _, line := range lines {
+		if err := c.enqueue("PRIVMSG " + channelID + " :" + line); err != nil {
+			return nil, err
+		}
+	}
+	return &types.SentMessage{ChannelID: channelID, Platform: Name, Raw: lines}, nil
+}
+
+// Edit implements platform.Platform. IRC messages cannot be edited.
+func (a *Adapter) Edit(string, string, types.MessageSend) (*types.SentMessage, error) {
+	return nil, fmt.Errorf("irc: editing messages: %w", errors.ErrUnsupported)
+}
+
+// Delete implements platform.Platform. IRC messages cannot be deleted.
+func (a *Adapter) Delete(string, string) error {
+	return fmt.Errorf("irc: deleting messages: %w", errors.ErrUnsupported)
+}
+
+// React implements platform.Platform. IRC has no reactions.
+func (a *Adapter) React(string, string, string) error {
+	return fmt.Errorf("irc: reactions: %w", errors.ErrUnsupported)
+}
+
+// SetPresence implements platform.Platform. Idle, DND and Busy mark the bot
+// away with the status name as message; other presences clear it.
+func (a *Adapter) SetPresence(status platform.Status) error {
+	c, err := a.current()
+	if err != nil {
+		return err
+	}
+
+	switch status.Presence {
+	case types.Idle, types.DND, types.Busy:
+		message := status.Name
+		if message == "" {
+			message = string(status.Presence)
+		}
+		return c.enqueue("AWAY :" + message)
+	default:
+		return c.enqueue("AWAY")
+	}
+}
+
+// Respond implements platform.Platform. Since IRC messages cannot be
+// edited, a response with edit set is sent as a new message.
+func (a *Adapter) Respond(e types.Event, msg types.MessageSend, _ *string) (*types.SentMessage, error) {
+	if e.ChannelID == "" {
+		return nil, fmt.Errorf("unsupported IRC context %T", e.Context)
+	}
+	return a.Send(e.ChannelID, msg)
+}
@@ -0,0 +1,349 @@
+package irc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/platform/irc/irctest"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// connect starts a server and an adapter registered with it as whiskercat
+// in #cats, and returns the channel the adapter's events arrive on.
+func connect(t *testing.T, config types.IRCConfig) (*irctest.Server, *Adapter, <-chan types.Event) {
+	t.Helper()
+	srv := irctest.NewServer()
+	t.Cleanup(srv.Close)
+
+	config.Server, config.Nick, config.Channels = srv.Addr(), "whiskercat", []string{"#cats"}
+	a, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan types.Event, 16)
+	a.HandleEvents(func(evt types.Event) { events <- evt })
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Disconnect(context.Background()) })
+
+	if evt := next(t, events); evt.Type != types.EventConnected {
+		t.Fatalf("first event is %s, want %s", evt.Type, types.EventConnected)
+	}
+	// The server echoes the bot's own JOIN.
+	if evt := next(t, events); evt.Type != types.EventMemberJoin || !evt.Bot {
+		t.Fatalf("second event is %s, want the bot joining #cats", evt.Type)
+	}
+	return srv, a, events
+}
+
+func next(t *testing.T, events <-chan types.Event) types.Event {
+	t.Helper()
+	select {
+	case evt := <-events:
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return types.Event{}
+	}
+}
+
+// waitLine waits for the client to send a line starting with prefix.
+func waitLine(t *testing.T, srv *irctest.Server, prefix string) string {
+	t.Helper()
+	line := make(chan string, 1)
+	go func() { line <- srv.WaitLine(prefix) }()
+	select {
+	case l := <-line:
+		return l
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %q; lines: %q", prefix, srv.Lines())
+		return ""
+	}
+}
+
+func TestEvents(t *testing.T) {
+	alice := types.User{ID: "alice", Username: "alice"}
+
+	tests := []struct {
+		name    string
+		inject  func(srv *irctest.Server)
+		want    types.EventType
+		channel string
+		check   func(t *testing.T, evt types.Event)
+	}{
+		{
+			name:    "channel message",
+			inject:  func(srv *irctest.Server) { srv.Privmsg("alice", "#cats", "hello there") },
+			want:    types.MessageCreate,
+			channel: "#cats",
+			check: func(t *testing.T, evt types.Event) {
+				msg := evt.Data.(types.MessageCallback)
+				if msg.ID != "" || msg.ChannelID != "#cats" || msg.Content != "hello there" || msg.Author != alice || msg.DM || msg.CreatedAt.IsZero() {
+					t.Errorf("message = %+v", msg)
+				}
+				if m, ok := evt.Context.(*Message); !ok || m.Command != "PRIVMSG" {
+					t.Errorf("Context = %#v, want the PRIVMSG", evt.Context)
+				}
+				if evt.Bot {
+					t.Error("message from alice is marked as the bot's")
+				}
+			},
+		},
+		{
+			name:    "private message",
+			inject:  func(srv *irctest.Server) { srv.Privmsg("alice", "whiskercat", "hi") },
+			want:    types.MessageCreate,
+			channel: "alice",
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); !msg.DM || msg.ChannelID != "alice" {
+					t.Errorf("message = %+v, want a DM in the query with alice", msg)
+				}
+			},
+		},
+		{
+			name:    "action",
+			inject:  func(srv *irctest.Server) { srv.Privmsg("alice", "#cats", "\x01ACTION waves\x01") },
+			want:    types.MessageCreate,
+			channel: "#cats",
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.Content != "waves" {
+					t.Errorf("content = %q, want waves", msg.Content)
+				}
+			},
+		},
+		{
+			name: "CTCP query",
+			inject: func(srv *irctest.Server) {
+				srv.Privmsg("alice", "whiskercat", "\x01VERSION\x01")
+				srv.Privmsg("alice", "#cats", "after")
+			},
+			want:    types.MessageCreate,
+			channel: "#cats",
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.Content != "after" {
+					t.Errorf("content = %q, want the message after the ignored query", msg.Content)
+				}
+			},
+		},
+		{
+			name:    "join",
+			inject:  func(srv *irctest.Server) { srv.Join("alice", "#cats") },
+			want:    types.EventMemberJoin,
+			channel: "#cats",
+			check: func(t *testing.T, evt types.Event) {
+				if m := evt.Data.(types.MemberCallback); m.User != alice || m.JoinedAt.IsZero() {
+					t.Errorf("member = %+v", m)
+				}
+			},
+		},
+		{
+			name:    "part",
+			inject:  func(srv *irctest.Server) { srv.Part("alice", "#cats", "bye") },
+			want:    types.EventMemberLeave,
+			channel: "#cats",
+			check: func(t *testing.T, evt types.Event) {
+				if m := evt.Data.(types.MemberCallback); m.User != alice {
+					t.Errorf("member = %+v", m)
+				}
+			},
+		},
+		{
+			name:    "kick",
+			inject:  func(srv *irctest.Server) { srv.Kick("op", "#cats", "alice") },
+			want:    types.EventMemberLeave,
+			channel: "#cats",
+			check: func(t *testing.T, evt types.Event) {
+				if m := evt.Data.(types.MemberCallback); m.User != alice {
+					t.Errorf("member = %+v", m)
+				}
+			},
+		},
+		{
+			name:    "quit",
+			inject:  func(srv *irctest.Server) { srv.Quit("alice", "Ping timeout") },
+			want:    types.EventMemberLeave,
+			channel: "",
+			check: func(t *testing.T, evt types.Event) {
+				if m := evt.Data.(types.MemberCallback); m.User != alice {
+					t.Errorf("member = %+v", m)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, a, events := connect(t, types.IRCConfig{})
+
+			tt.inject(srv)
+
+			evt := next(t, events)
+			if evt.Type != tt.want || evt.Name != string(tt.want) {
+				t.Fatalf("event %s (%s), want %s", evt.Type, evt.Name, tt.want)
+			}
+			if evt.Platform != Name || evt.Session != a || evt.ChannelID != tt.channel {
+				t.Errorf("event from %q in %q, want IRC in %q", evt.Platform, evt.ChannelID, tt.channel)
+			}
+			tt.check(t, evt)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   func(srv *irctest.Server)
+		config   types.IRCConfig
+		wantErr  string // empty if registration must succeed
+		wantNick string
+		sent     []string // lines the client must have sent, in order
+	}{
+		{
+			name:     "plain",
+			wantNick: "whiskercat",
+			sent:     []string{"NICK whiskercat", "USER whiskercat 0 * :whiskercat"},
+		},
+		{
+			name:     "SASL",
+			server:   func(srv *irctest.Server) { srv.Accounts = map[string]string{"cat": "secret"} },
+			config:   types.IRCConfig{SASLUser: "cat", SASLPassword: "secret"},
+			wantNick: "whiskercat",
+			sent:     []string{"CAP REQ :sasl", "AUTHENTICATE PLAIN", "AUTHENTICATE Y2F0AGNhdABzZWNyZXQ=", "CAP END"},
+		},
+		{
+			name:    "SASL with the wrong password",
+			server:  func(srv *irctest.Server) { srv.Accounts = map[string]string{"cat": "secret"} },
+			config:  types.IRCConfig{SASLUser: "cat", SASLPassword: "guess"},
+			wantErr: "SASL authentication failed",
+		},
+		{
+			name:    "SASL unsupported",
+			config:  types.IRCConfig{SASLUser: "cat", SASLPassword: "secret"},
+			wantErr: "server does not support SASL",
+		},
+		{
+			name:     "server password",
+			server:   func(srv *irctest.Server) { srv.Password = "hunter2" },
+			config:   types.IRCConfig{Password: "hunter2"},
+			wantNick: "whiskercat",
+			sent:     []string{"PASS hunter2", "NICK whiskercat"},
+		},
+		{
+			name:    "wrong server password",
+			server:  func(srv *irctest.Server) { srv.Password = "hunter2" },
+			config:  types.IRCConfig{Password: "guess"},
+			wantErr: "Password incorrect",
+		},
+		{
+			name:     "nick in use",
+			server:   func(srv *irctest.Server) { srv.TakeNick("whiskercat") },
+			wantNick: "whiskercat_",
+			sent:     []string{"NICK whiskercat", "NICK whiskercat_"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := irctest.NewServer()
+			defer srv.Close()
+			if tt.server != nil {
+				tt.server(srv)
+			}
+
+			config := tt.config
+			config.Server, config.Nick = srv.Addr(), "whiskercat"
+			a, err := New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = a.Connect(ctx)
+			defer a.Disconnect(context.Background())
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Connect = %v, want an error containing %q", err, tt.wantErr)
+				}
+				if _, err := a.current(); err == nil {
+					t.Error("adapter is connected after a failed registration")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Connect = %v", err)
+			}
+			if a.Nick() != tt.wantNick || srv.Nick() != tt.wantNick {
+				t.Errorf("registered as %q (server: %q), want %q", a.Nick(), srv.Nick(), tt.wantNick)
+			}
+
+			lines, i := srv.Lines(), 0
+			for _, line := range lines {
+				if i < len(tt.sent) && line == tt.sent[i] {
+					i++
+				}
+			}
+			if i < len(tt.sent) {
+				t.Errorf("sent %q, want %q in order", lines, tt.sent)
+			}
+		})
+	}
+}
+
+func TestRejoin(t *testing.T) {
+	delay := rejoinDelay
+	rejoinDelay = 10 * time.Millisecond
+	t.Cleanup(func() { rejoinDelay = delay })
+
+	srv, a, events := connect(t, types.IRCConfig{})
+	if !srv.Joined("#cats") {
+		t.Fatal("did not join #cats on connect")
+	}
+
+	srv.Kick("op", "#cats", "whiskercat")
+	if evt := next(t, events); evt.Type != types.EventMemberLeave || evt.Data.(types.MemberCallback).User.ID != "whiskercat" {
+		t.Fatalf("event %s (%+v), want the bot leaving #cats", evt.Type, evt.Data)
+	}
+	if evt := next(t, events); evt.Type != types.EventMemberJoin || !evt.Bot || evt.ChannelID != "#cats" {
+		t.Fatalf("event %s in %s, want the bot rejoining #cats", evt.Type, evt.ChannelID)
+	}
+
+	// Channels the bot parted are not rejoined.
+	if err := a.Part("#cats", "bye"); err != nil {
+		t.Fatal(err)
+	}
+	next(t, events)
+	srv.Join("whiskercat", "#cats")
+	next(t, events)
+	srv.Kick("op", "#cats", "whiskercat")
+	next(t, events)
+	select {
+	case evt := <-events:
+		t.Errorf("got %s in %s after being kicked from a parted channel", evt.Type, evt.ChannelID)
+	case <-time.After(10 * rejoinDelay):
+	}
+}
+
+func TestFloodPacing(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	srv, a, _ := connect(t, types.IRCConfig{FloodDelay: delay})
+	// Let the burst used by the JOIN refill.
+	time.Sleep(floodBurst * delay)
+
+	start := time.Now()
+	if _, err := a.Send("#cats", types.MessageSend{Content: "1\n2\n3\n4\n5\n6"}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitLine(t, srv, "PRIVMSG #cats :4")
+	if burst := time.Since(start); burst >= delay {
+		t.Errorf("first %d lines took %s, want them sent at once", floodBurst, burst)
+	}
+	waitLine(t, srv, "PRIVMSG #cats :6")
+	if paced := time.Since(start); paced < 2*delay {
+		t.Errorf("6 lines took %s, want at least %s after the burst", paced, 2*delay)
+	}
+}
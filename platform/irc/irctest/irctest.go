@@ -0,0 +1,301 @@
+// Package irctest provides an in-process fake IRC server for testing the
+// irc adapter. It accepts one client at a time, handles registration
+// (including SASL PLAIN), PING, JOIN and PART, and records everything the
+// client sends.
+//
+//	srv := irctest.NewServer()
+//	defer srv.Close()
+//	adapter, _ := irc.New(types.IRCConfig{Server: srv.Addr(), Nick: "whiskercat", Channels: []string{"#cats"}})
+//	...
+//	srv.Privmsg("alice", "#cats", "ping")
+package irctest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Server is a fake IRC server.
+type Server struct {
+	listener net.Listener
+
+	// Accounts maps SASL account names to passwords. SASL is refused when
+	// it is nil.
+	Accounts map[string]string
+
+	// Password, when set, must be sent with PASS before registering.
+	Password string
+
+	mu       sync.Mutex
+	client   net.Conn
+	nick     string
+	lines    []string
+	channels map[string]bool
+	taken    map[string]bool
+	changed  *sync.Cond
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server listening on a local TCP port.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("irctest: failed to listen: " + err.Error())
+	}
+
+	s := &Server{
+		listener: listener,
+		channels: make(map[string]bool),
+		taken:    make(map[string]bool),
+	}
+	s.changed = sync.NewCond(&s.mu)
+
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+// Addr returns the host:port to use as types.IRCConfig.Server.
+func (s *Server) Addr() string { return s.listener.Addr().String() }
+
+// Close stops the server and drops the client.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.Drop()
+	s.wg.Wait()
+}
+
+// Drop closes the client connection, as a netsplit or server restart would.
+func (s *Server) Drop() {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+	if client != nil {
+		_ = client.Close()
+	}
+}
+
+// TakeNick makes nick unavailable, so registering with it fails with
+// ERR_NICKNAMEINUSE.
+func (s *Server) TakeNick(nick string) {
+	s.mu.Lock()
+	s.taken[strings.ToLower(nick)] = true
+	s.mu.Unlock()
+}
+
+// Nick returns the client's registered nickname.
+func (s *Server) Nick() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nick
+}
+
+// Lines returns every line the client has sent, in order.
+func (s *Server) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+// WaitLine blocks until the client has sent a line starting with prefix
+// and returns it.
+func (s *Server) WaitLine(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for seen := 0; ; {
+		for ; seen < len(s.lines); seen++ {
+			if strings.HasPrefix(s.lines[seen], prefix) {
+				return s.lines[seen]
+			}
+		}
+		s.changed.Wait()
+	}
+}
+
+// Joined reports whether the client is in channel.
+func (s *Server) Joined(channel string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channels[strings.ToLower(channel)]
+}
+
+// Privmsg delivers a message from nick to target, a channel or the client.
+func (s *Server) Privmsg(nick, target, text string) {
+	s.send(":" + mask(nick) + " PRIVMSG " + target + " :" + text)
+}
+
+// Join announces that nick joined channel.
+func (s *Server) Join(nick, channel string) {
+	s.send(":" + mask(nick) + " JOIN " + channel)
+}
+
+// Part announces that nick left channel.
+func (s *Server) Part(nick, channel, reason string) {
+	s.send(":" + mask(nick) + " PART " + channel + " :" + reason)
+}
+
+// Quit announces that nick quit the network.
+func (s *Server) Quit(nick, reason string) {
+	s.send(":" + mask(nick) + " QUIT :" + reason)
+}
+
+// Kick removes nick from channel on behalf of op. Kicking the client
+// removes it from the channel.
+func (s *Server) Kick(op, channel, nick string) {
+	s.mu.Lock()
+	if strings.EqualFold(nick, s.nick) {
+		delete(s.channels, strings.ToLower(channel))
+	}
+	s.mu.Unlock()
+	s.send(":" + mask(op) + " KICK " + channel + " " + nick + " :Kicked")
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.client != nil {
+			_ = s.client.Close()
+		}
+		s.client = conn
+		s.nick = ""
+		s.channels = make(map[string]bool)
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	var (
+		nick, pass    string
+		capNegotiated bool
+		userSent      bool
+		registered    bool
+	)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	welcome := func() {
+		if registered || nick == "" || !userSent || capNegotiated {
+			return
+		}
+		if s.Password != "" && pass != s.Password {
+			reply(":irc.test 464 * :Password incorrect")
+			reply("ERROR :Closing link: bad password")
+			_ = conn.Close()
+			return
+		}
+		registered = true
+		s.mu.Lock()
+		s.nick = nick
+		s.mu.Unlock()
+		reply(":irc.test 001 " + nick + " :Welcome to the test network " + nick)
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		s.mu.Lock()
+		s.lines = append(s.lines, line)
+		s.changed.Broadcast()
+		s.mu.Unlock()
+
+		command, rest, _ := strings.Cut(line, " ")
+		params := strings.TrimPrefix(rest, ":")
+		switch strings.ToUpper(command) {
+		case "PASS":
+			pass = params
+		case "CAP":
+			if strings.HasPrefix(rest, "REQ") {
+				capNegotiated = true
+				if s.Accounts == nil {
+					reply(":irc.test CAP * NAK :sasl")
+				} else {
+					reply(":irc.test CAP * ACK :sasl")
+				}
+			} else if rest == "END" {
+				capNegotiated = false
+				welcome()
+			}
+		case "AUTHENTICATE":
+			if rest == "PLAIN" {
+				reply("AUTHENTICATE +")
+				continue
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(rest)
+			parts := strings.Split(string(decoded), "\x00")
+			if len(parts) == 3 && s.Accounts[parts[1]] == parts[2] && parts[2] != "" {
+				reply(":irc.test 900 * * " + parts[1] + " :You are now logged in")
+				reply(":irc.test 903 * :SASL authentication successful")
+			} else {
+				reply(":irc.test 904 * :SASL authentication failed")
+			}
+		case "NICK":
+			s.mu.Lock()
+			taken := s.taken[strings.ToLower(params)]
+			s.mu.Unlock()
+			if taken {
+				reply(":irc.test 433 * " + params + " :Nickname is already in use")
+				continue
+			}
+			if registered {
+				reply(":" + mask(nick) + " NICK :" + params)
+				s.mu.Lock()
+				s.nick = params
+				s.mu.Unlock()
+			}
+			nick = params
+			welcome()
+		case "USER":
+			userSent = true
+			welcome()
+		case "PING":
+			reply(":irc.test PONG irc.test :" + params)
+		case "JOIN":
+			for _, channel := range strings.Split(params, ",") {
+				s.mu.Lock()
+				s.channels[strings.ToLower(channel)] = true
+				s.mu.Unlock()
+				reply(":" + mask(nick) + " JOIN " + channel)
+			}
+		case "PART":
+			channel, _, _ := strings.Cut(params, " ")
+			s.mu.Lock()
+			delete(s.channels, strings.ToLower(channel))
+			s.mu.Unlock()
+			reply(":" + mask(nick) + " " + line)
+		case "QUIT":
+			reply("ERROR :Closing link")
+			return
+		}
+	}
+}
+
+func (s *Server) send(line string) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+	if client != nil {
+		_, _ = client.Write([]byte(line + "\r\n"))
+	}
+}
+
+func mask(nick string) string {
+	return nick + "!" + strings.ToLower(nick) + "@test"
+}
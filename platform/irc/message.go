@@ -0,0 +1,60 @@
+package irc
+
+import "strings"
+
+// Message is a parsed IRC protocol line. It is used as the
+// types.Event.Context of IRC events.
+type Message struct {
+	Tags    string   // Raw IRCv3 message tags, without the leading '@'
+	Prefix  string   // Source of the message, e.g. nick!user@host
+	Command string   // Command or three-digit numeric
+	Params  []string // Parameters, including the trailing one
+}
+
+// parseMessage parses a line without its trailing CRLF.
+func parseMessage(line string) *Message {
+	m := &Message{}
+	if strings.HasPrefix(line, "@") {
+		m.Tags, line, _ = strings.Cut(line[1:], " ")
+		line = strings.TrimLeft(line, " ")
+	}
+	if strings.HasPrefix(line, ":") {
+		m.Prefix, line, _ = strings.Cut(line[1:], " ")
+		line = strings.TrimLeft(line, " ")
+	}
+
+	for line != "" {
+		if strings.HasPrefix(line, ":") {
+			m.Params = append(m.Params, line[1:])
+			break
+		}
+		var param string
+		param, line, _ = strings.Cut(line, " ")
+		line = strings.TrimLeft(line, " ")
+		if m.Command == "" {
+			m.Command = strings.ToUpper(param)
+		} else {
+			m.Params = append(m.Params, param)
+		}
+	}
+	return m
+}
+
+// Nick returns the nickname part of the prefix.
+func (m *Message) Nick() string {
+	nick, _, _ := strings.Cut(m.Prefix, "!")
+	return nick
+}
+
+// Param returns the i-th parameter, or "" if there are fewer.
+func (m *Message) Param(i int) string {
+	if i < len(m.Params) {
+		return m.Params[i]
+	}
+	return ""
+}
+
+// isChannel reports whether target names a channel rather than a user.
+func isChannel(target string) bool {
+	return target != "" && strings.ContainsRune("#&+!", rune(target[0]))
+}
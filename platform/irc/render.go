@@ -0,0 +1,83 @@
+package irc
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// maxLineLength is the number of message bytes sent per PRIVMSG. Servers
+// cut lines at 512 bytes including the prefix they add when relaying, so
+// this leaves room for a long hostmask and channel name.
+const maxLineLength = 400
+
+// renderLines converts an outgoing message into the text lines to send.
+// IRC has no formatting worth relying on, so an embed becomes plain lines:
+// the title (with its link), description, "name: value" fields, image and
+// footer.
+func renderLines(msg types.MessageSend) []string {
+	var text []string
+	if msg.Content != "" {
+		text = append(text, msg.Content)
+	}
+
+	if embed := msg.Embed; embed != nil {
+		if embed.Title != "" {
+			title := embed.Title
+			if embed.URL != nil {
+				title += " <" + *embed.URL + ">"
+			}
+			text = append(text, title)
+		}
+		if embed.Description != "" {
+			text = append(text, embed.Description)
+		}
+		if embed.Fields != nil {
+			for _, f := range *embed.Fields {
+				text = append(text, f.Name+": "+f.Value)
+			}
+		}
+		if embed.PhotoURL != nil {
+			text = append(text, *embed.PhotoURL)
+		}
+		if embed.Footer != nil && embed.Footer.Text != "" {
+			text = append(text, "-- "+embed.Footer.Text)
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.Join(text, "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			continue
+		}
+		lines = append(lines, splitLine(line, maxLineLength)...)
+	}
+	return lines
+}
+
+// splitLine breaks a line into chunks of at most max bytes, preferring to
+// break at spaces and never splitting a valid UTF-8 sequence.
+func splitLine(line string, max int) []string {
+	var chunks []string
+	for len(line) > max {
+		cut := max
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			// Invalid UTF-8 without a rune start; cut it anywhere.
+			cut = max
+		}
+		if i := strings.LastIndexByte(line[:cut], ' '); i > max/2 {
+			cut = i
+		}
+		chunks = append(chunks, line[:cut])
+		line = strings.TrimLeft(line[cut:], " ")
+	}
+	if line != "" {
+		chunks = append(chunks, line)
+	}
+	return chunks
+}
@@ -0,0 +1,36 @@
+package irc
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		max  int
+		want []string
+	}{
+		{"short", "hello", 10, []string{"hello"}},
+		{"empty", "", 10, nil},
+		{"at a space", "hello there world", 12, []string{"hello there", "world"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"inside a rune", "aé", 2, []string{"a", "é"}},
+		{"continuation bytes", strings.Repeat("\x80", 5), 2, []string{"\x80\x80", "\x80\x80", "\x80"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitLine(tt.line, tt.max)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitLine(%q, %d) = %q, want %q", tt.line, tt.max, got, tt.want)
+			}
+			for _, chunk := range got {
+				if len(chunk) > tt.max {
+					t.Errorf("chunk %q is longer than %d bytes", chunk, tt.max)
+				}
+			}
+		})
+	}
+}
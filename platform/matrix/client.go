@@ -0,0 +1,129 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// apiPrefix is the client-server API version used for every request.
+const apiPrefix = "/_matrix/client/v3"
+
+// client is a minimal Matrix client-server API client.
+type client struct {
+	homeserver string
+	token      string
+	http       *http.Client
+	txn        atomic.Int64
+}
+
+// Error is returned when the homeserver answers with a Matrix error body.
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"errcode"`
+	Message string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("matrix: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// do sends a request to the homeserver and decodes the JSON response into out.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := c.homeserver + apiPrefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := &Error{Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(apiErr)
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// txnID returns a transaction ID unique to this client.
+func (c *client) txnID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatInt(c.txn.Add(1), 10)
+}
+
+func (c *client) whoami(ctx context.Context) (string, error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+	err := c.do(ctx, http.MethodGet, "/account/whoami", nil, nil, &resp)
+	return resp.UserID, err
+}
+
+// sendEvent sends a room event and returns its event ID.
+func (c *client) sendEvent(ctx context.Context, roomID, eventType string, content any) (string, error) {
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	path := "/rooms/" + url.PathEscape(roomID) + "/send/" + url.PathEscape(eventType) + "/" + url.PathEscape(c.txnID())
+	err := c.do(ctx, http.MethodPut, path, nil, content, &resp)
+	return resp.EventID, err
+}
+
+func (c *client) redact(ctx context.Context, roomID, eventID string) error {
+	path := "/rooms/" + url.PathEscape(roomID) + "/redact/" + url.PathEscape(eventID) + "/" + url.PathEscape(c.txnID())
+	return c.do(ctx, http.MethodPut, path, nil, struct{}{}, nil)
+}
+
+func (c *client) setPresence(ctx context.Context, userID, presence, message string) error {
+	body := map[string]string{"presence": presence}
+	if message != "" {
+		body["status_msg"] = message
+	}
+	return c.do(ctx, http.MethodPut, "/presence/"+url.PathEscape(userID)+"/status", nil, body, nil)
+}
+
+func (c *client) sync(ctx context.Context, since string, timeout time.Duration) (*syncResponse, error) {
+	query := url.Values{}
+	query.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	if since != "" {
+		query.Set("since", since)
+	}
+
+	var resp syncResponse
+	if err := c.do(ctx, http.MethodGet, "/sync", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
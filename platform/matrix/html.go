@@ -0,0 +1,67 @@
+package matrix
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// renderMessage converts an outgoing message into m.room.message content.
+// Embeds are rendered into an HTML formatted_body with a plain-text body as
+// fallback for clients without HTML support.
+func renderMessage(msg types.MessageSend) *messageContent {
+	content := &messageContent{MsgType: "m.text", Body: msg.Content}
+	if msg.Embed == nil {
+		return content
+	}
+
+	var plain, rich strings.Builder
+	if msg.Content != "" {
+		plain.WriteString(msg.Content + "\n\n")
+		rich.WriteString("<p>" + escape(msg.Content) + "</p>")
+	}
+
+	embed := msg.Embed
+	color := fmt.Sprintf("#%06X", embed.Color)
+	rich.WriteString(`<blockquote data-mx-border-color="` + color + `">`)
+
+	if embed.Title != "" {
+		plain.WriteString(embed.Title + "\n")
+		title := "<strong>" + escape(embed.Title) + "</strong>"
+		if embed.URL != nil {
+			title = `<a href="` + html.EscapeString(*embed.URL) + `">` + title + "</a>"
+		}
+		rich.WriteString("<p>" + title + "</p>")
+	}
+	if embed.Description != "" {
+		plain.WriteString(embed.Description + "\n")
+		rich.WriteString("<p>" + escape(embed.Description) + "</p>")
+	}
+	if embed.Fields != nil {
+		for _, f := range *embed.Fields {
+			plain.WriteString(f.Name + ": " + f.Value + "\n")
+			rich.WriteString("<p><strong>" + escape(f.Name) + "</strong><br>" + escape(f.Value) + "</p>")
+		}
+	}
+	if embed.PhotoURL != nil {
+		plain.WriteString(*embed.PhotoURL + "\n")
+		rich.WriteString(`<p><a href="` + html.EscapeString(*embed.PhotoURL) + `">` + html.EscapeString(*embed.PhotoURL) + "</a></p>")
+	}
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		plain.WriteString(embed.Footer.Text + "\n")
+		rich.WriteString("<p><sub>" + escape(embed.Footer.Text) + "</sub></p>")
+	}
+	rich.WriteString("</blockquote>")
+
+	content.Body = strings.TrimRight(plain.String(), "\n")
+	content.Format = "org.matrix.custom.html"
+	content.FormattedBody = rich.String()
+	return content
+}
+
+// escape HTML-escapes text and keeps its line breaks.
+func escape(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
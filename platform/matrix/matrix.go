@@ -0,0 +1,233 @@
+// Package matrix adapts the Matrix client-server API to the
+// platform.Platform interface. Rooms are treated as channels.
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Name is the value used in types.Event.Platform for Matrix events.
+const Name = "Matrix"
+
+func init() {
+	platform.Register(Name, func(config *types.AuthConfig) (platform.Platform, error) {
+		if !config.Matrix.Enabled() {
+			return nil, nil
+		}
+		return New(*config.Matrix)
+	})
+}
+
+// Adapter implements platform.Platform on top of the Matrix /sync API.
+type Adapter struct {
+	client *client
+	userID string
+
+	ready platform.ReadySignal
+
+	runMu  sync.Mutex
+	cancel context.CancelFunc
+	runID  int
+	since  string
+
+	mu   sync.RWMutex
+	sink func(types.Event)
+}
+
+// New creates a Matrix adapter. Nothing is requested until Connect.
+func New(config types.MatrixConfig) (*Adapter, error) {
+	if config.Homeserver == "" {
+		return nil, errors.New("no homeserver provided")
+	}
+	if config.AccessToken == "" {
+		return nil, errors.New("no access token provided")
+	}
+
+	return &Adapter{
+		client: &client{
+			homeserver: strings.TrimRight(config.Homeserver, "/"),
+			token:      config.AccessToken,
+			http:       &http.Client{Timeout: syncTimeout + 10*time.Second},
+		},
+		userID: config.UserID,
+	}, nil
+}
+
+// Name implements platform.Platform.
+func (a *Adapter) Name() string { return Name }
+
+// UserID returns the Matrix ID the adapter is logged in as.
+func (a *Adapter) UserID() string { return a.userID }
+
+// Connect implements platform.Platform. It verifies the access token and
+// starts the sync loop; a reconnect continues from the last sync position.
+func (a *Adapter) Connect(ctx context.Context) error {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+
+	if a.cancel != nil {
+		return errors.New("already connected")
+	}
+
+	userID, err := a.client.whoami(ctx)
+	if err != nil {
+		return err
+	}
+	a.userID = userID
+
+	a.ready.Reset()
+	runCtx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.runID++
+	runID := a.runID
+
+	go func() {
+		a.run(runCtx, a.getSince())
+
+		// Let the supervisor reconnect once the loop has given up.
+		a.runMu.Lock()
+		if a.runID == runID {
+			a.cancel = nil
+		}
+		a.runMu.Unlock()
+	}()
+	return nil
+}
+
+// Disconnect implements platform.Platform.
+func (a *Adapter) Disconnect(_ context.Context) error {
+	a.runMu.Lock()
+	cancel := a.cancel
+	a.cancel = nil
+	a.runMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		a.emitState(types.EventDisconnected)
+	}
+	return nil
+}
+
+// Ready implements platform.Platform.
+func (a *Adapter) Ready() <-chan struct{} { return a.ready.Done() }
+
+// HandleEvents implements platform.Platform.
+func (a *Adapter) HandleEvents(sink func(types.Event)) {
+	a.mu.Lock()
+	a.sink = sink
+	a.mu.Unlock()
+}
+
+func (a *Adapter) emit(evt types.Event) {
+	a.mu.RLock()
+	sink := a.sink
+	a.mu.RUnlock()
+
+	if sink != nil {
+		sink(evt)
+	}
+}
+
+func (a *Adapter) emitState(eventType types.EventType) {
+	a.emit(types.Event{
+		Name:     string(eventType),
+		Type:     eventType,
+		Platform: Name,
+		Session:  a,
+	})
+}
+
+func (a *Adapter) getSince() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.since
+}
+
+func (a *Adapter) setSince(since string) {
+	a.mu.Lock()
+	a.since = since
+	a.mu.Unlock()
+}
+
+// Send implements platform.Platform.
+func (a *Adapter) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	return a.send(channelID, renderMessage(msg))
+}
+
+// Edit implements platform.Platform. Matrix edits are new events that
+// replace the original through an m.replace relation.
+func (a *Adapter) Edit(channelID, messageID string, msg types.MessageSend) (*types.SentMessage, error) {
+	newContent := renderMessage(msg)
+	content := *newContent
+	content.Body = "* " + newContent.Body
+	if content.FormattedBody != "" {
+		content.FormattedBody = "* " + newContent.FormattedBody
+	}
+	content.NewContent = newContent
+	content.RelatesTo = &relatesTo{RelType: "m.replace", EventID: messageID}
+
+	if _, err := a.send(channelID, &content); err != nil {
+		return nil, err
+	}
+	// Edits keep referring to the original event.
+	return &types.SentMessage{ID: messageID, ChannelID: channelID, Platform: Name}, nil
+}
+
+// Delete implements platform.Platform by redacting the event.
+func (a *Adapter) Delete(channelID, messageID string) error {
+	return a.client.redact(context.Background(), channelID, messageID)
+}
+
+// React implements platform.Platform with an m.annotation relation.
+func (a *Adapter) React(channelID, messageID, emoji string) error {
+	_, err := a.client.sendEvent(context.Background(), channelID, "m.reaction", map[string]any{
+		"m.relates_to": relatesTo{RelType: "m.annotation", EventID: messageID, Key: emoji},
+	})
+	return err
+}
+
+// SetPresence implements platform.Platform.
+func (a *Adapter) SetPresence(status platform.Status) error {
+	presence := "online"
+	switch status.Presence {
+	case types.Idle, types.DND, types.Busy:
+		presence = "unavailable"
+	case types.Invisible:
+		presence = "offline"
+	}
+	return a.client.setPresence(context.Background(), a.userID, presence, status.Name)
+}
+
+// Respond implements platform.Platform. Replies to messages carry an
+// m.in_reply_to relation.
+func (a *Adapter) Respond(e types.Event, msg types.MessageSend, edit *string) (*types.SentMessage, error) {
+	if e.ChannelID == "" {
+		return nil, fmt.Errorf("unsupported Matrix context %T", e.Context)
+	}
+	if edit != nil {
+		return a.Edit(e.ChannelID, *edit, msg)
+	}
+
+	content := renderMessage(msg)
+	if ctx, ok := e.Context.(*Event); ok && e.Type == types.MessageCreate {
+		content.RelatesTo = &relatesTo{InReplyTo: &eventRef{EventID: ctx.ID}}
+	}
+	return a.send(e.ChannelID, content)
+}
+
+func (a *Adapter) send(roomID string, content *messageContent) (*types.SentMessage, error) {
+	eventID, err := a.client.sendEvent(context.Background(), roomID, "m.room.message", content)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SentMessage{ID: eventID, ChannelID: roomID, Platform: Name, Raw: content}, nil
+}
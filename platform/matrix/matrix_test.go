@@ -0,0 +1,251 @@
+package matrix_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/platform/matrix"
+	"github.com/luvixsocial/whiskercat/platform/matrix/matrixtest"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+const room = "!room:test"
+
+// connect starts a homeserver and an adapter synced to it, and returns the
+// channel the adapter's events arrive on.
+func connect(t *testing.T) (*matrixtest.Server, *matrix.Adapter, <-chan types.Event) {
+	t.Helper()
+	hs := matrixtest.NewServer("@bot:test", "token")
+	t.Cleanup(hs.Close)
+
+	a, err := matrix.New(types.MatrixConfig{Homeserver: hs.URL, AccessToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan types.Event, 16)
+	a.HandleEvents(func(evt types.Event) { events <- evt })
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Disconnect(context.Background()) })
+
+	if evt := next(t, events); evt.Type != types.EventConnected {
+		t.Fatalf("first event is %s, want %s", evt.Type, types.EventConnected)
+	}
+	return hs, a, events
+}
+
+func next(t *testing.T, events <-chan types.Event) types.Event {
+	t.Helper()
+	select {
+	case evt := <-events:
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return types.Event{}
+	}
+}
+
+func TestSync(t *testing.T) {
+	alice := types.User{ID: "@alice:test", Username: "alice"}
+
+	tests := []struct {
+		name   string
+		inject func(hs *matrixtest.Server) string
+		want   types.EventType
+		check  func(t *testing.T, evt types.Event, id string)
+	}{
+		{
+			name:   "message",
+			inject: func(hs *matrixtest.Server) string { return hs.Message(room, alice.ID, "hello") },
+			want:   types.MessageCreate,
+			check: func(t *testing.T, evt types.Event, id string) {
+				msg := evt.Data.(types.MessageCallback)
+				if msg.ID != id || msg.ChannelID != room || msg.Content != "hello" || msg.Author != alice || msg.CreatedAt.IsZero() {
+					t.Errorf("message = %+v", msg)
+				}
+				if e, ok := evt.Context.(*matrix.Event); !ok || e.ID != id {
+					t.Errorf("Context = %#v, want the event", evt.Context)
+				}
+				if evt.Bot || evt.Self {
+					t.Error("message from alice is marked as the bot's")
+				}
+			},
+		},
+		{
+			name: "reply",
+			inject: func(hs *matrixtest.Server) string {
+				return hs.Inject(room, alice.ID, "m.room.message", map[string]any{
+					"msgtype":      "m.text",
+					"body":         "> <@bob:test> hi\n> there\n\nhello",
+					"m.relates_to": map[string]any{"m.in_reply_to": map[string]any{"event_id": "$parent"}},
+				})
+			},
+			want: types.MessageCreate,
+			check: func(t *testing.T, evt types.Event, _ string) {
+				if msg := evt.Data.(types.MessageCallback); msg.Content != "hello" || msg.ReplyTo != "$parent" {
+					t.Errorf("reply %q to %q, want %q to $parent", msg.Content, msg.ReplyTo, "hello")
+				}
+			},
+		},
+		{
+			name:   "own message",
+			inject: func(hs *matrixtest.Server) string { return hs.Message(room, "@bot:test", "echo") },
+			want:   types.MessageCreate,
+			check: func(t *testing.T, evt types.Event, _ string) {
+				if !evt.Bot || !evt.Self {
+					t.Errorf("Bot = %v, Self = %v; want the bot's own message", evt.Bot, evt.Self)
+				}
+			},
+		},
+		{
+			name:   "edit",
+			inject: func(hs *matrixtest.Server) string { return hs.Edit(room, alice.ID, "$original", "new") },
+			want:   types.MessageUpdate,
+			check: func(t *testing.T, evt types.Event, _ string) {
+				msg := evt.Data.(types.MessageCallback)
+				if msg.ID != "$original" || msg.Content != "new" || msg.EditedAt.IsZero() || !msg.CreatedAt.IsZero() {
+					t.Errorf("edit = %+v, want $original edited to %q", msg, "new")
+				}
+			},
+		},
+		{
+			name:   "redaction",
+			inject: func(hs *matrixtest.Server) string { return hs.Redact(room, alice.ID, "$original") },
+			want:   types.MessageDelete,
+			check: func(t *testing.T, evt types.Event, _ string) {
+				if msg := evt.Data.(types.MessageCallback); msg.ID != "$original" || msg.ChannelID != room {
+					t.Errorf("deleted %+v, want $original", msg)
+				}
+			},
+		},
+		{
+			name: "redaction in the content",
+			inject: func(hs *matrixtest.Server) string {
+				return hs.Inject(room, alice.ID, "m.room.redaction", map[string]any{"redacts": "$original"})
+			},
+			want: types.MessageDelete,
+			check: func(t *testing.T, evt types.Event, _ string) {
+				if msg := evt.Data.(types.MessageCallback); msg.ID != "$original" {
+					t.Errorf("deleted %q, want $original", msg.ID)
+				}
+			},
+		},
+		{
+			name:   "reaction",
+			inject: func(hs *matrixtest.Server) string { return hs.React(room, alice.ID, "$original", "👍") },
+			want:   types.ReactionAdd,
+			check: func(t *testing.T, evt types.Event, _ string) {
+				r := evt.Data.(types.ReactionCallback)
+				if r.MessageID != "$original" || r.ChannelID != room || r.User != alice || r.Emoji.Name != "👍" {
+					t.Errorf("reaction = %+v", r)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs, a, events := connect(t)
+
+			id := tt.inject(hs)
+
+			evt := next(t, events)
+			if evt.Type != tt.want || evt.Name != string(tt.want) {
+				t.Fatalf("event %s (%s), want %s", evt.Type, evt.Name, tt.want)
+			}
+			if evt.Platform != matrix.Name || evt.Session != a || evt.ChannelID != room {
+				t.Errorf("event from %q in %q, want Matrix in %s", evt.Platform, evt.ChannelID, room)
+			}
+			tt.check(t, evt, id)
+		})
+	}
+}
+
+func TestSyncSkipsBacklog(t *testing.T) {
+	hs := matrixtest.NewServer("@bot:test", "token")
+	defer hs.Close()
+	hs.Message(room, "@alice:test", "before the bot started")
+
+	a, err := matrix.New(types.MatrixConfig{Homeserver: hs.URL, AccessToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan types.Event, 16)
+	a.HandleEvents(func(evt types.Event) { events <- evt })
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Disconnect(context.Background())
+
+	next(t, events)
+	hs.Message(room, "@alice:test", "after")
+	if msg, ok := next(t, events).Data.(types.MessageCallback); !ok || msg.Content != "after" {
+		t.Errorf("delivered %+v, want only the message sent after connecting", msg)
+	}
+}
+
+func TestSendEmbed(t *testing.T) {
+	url, photo := "https://example.com/a?b&c", "https://example.com/cat.png"
+
+	tests := []struct {
+		name   string
+		msg    types.MessageSend
+		body   string
+		format string
+		html   string
+	}{
+		{
+			name: "plain",
+			msg:  types.MessageSend{Content: "hello <world>"},
+			body: "hello <world>",
+		},
+		{
+			name: "embed",
+			msg: types.MessageSend{Content: "Status:", Embed: &types.Embed{
+				Title:       "Cats & dogs",
+				URL:         &url,
+				Description: "line one\nline <two>",
+				Fields:      &[]types.EmbedField{{Name: "Up", Value: "yes"}},
+				PhotoURL:    &photo,
+				Footer:      &types.EmbedFooter{Text: "footer"},
+				Color:       0xff8800,
+			}},
+			body:   "Status:\n\nCats & dogs\nline one\nline <two>\nUp: yes\n" + photo + "\nfooter",
+			format: "org.matrix.custom.html",
+			html: `<p>Status:</p><blockquote data-mx-border-color="#FF8800">` +
+				`<p><a href="https://example.com/a?b&amp;c"><strong>Cats &amp; dogs</strong></a></p>` +
+				`<p>line one<br>line &lt;two&gt;</p>` +
+				`<p><strong>Up</strong><br>yes</p>` +
+				`<p><a href="` + photo + `">` + photo + `</a></p>` +
+				`<p><sub>footer</sub></p></blockquote>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs, a, _ := connect(t)
+
+			sent, err := a.Send(room, tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := hs.Sent()
+			if len(events) != 1 || events[0].ID != sent.ID || events[0].Type != "m.room.message" {
+				t.Fatalf("sent %+v, want one m.room.message with ID %s", events, sent.ID)
+			}
+			content := events[0].Content
+			if content["body"] != tt.body {
+				t.Errorf("body = %q, want %q", content["body"], tt.body)
+			}
+			if format, _ := content["format"].(string); format != tt.format {
+				t.Errorf("format = %q, want %q", format, tt.format)
+			}
+			if html, _ := content["formatted_body"].(string); html != tt.html {
+				t.Errorf("formatted_body = %q, want %q", html, tt.html)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Package matrixtest provides an in-process stand-in for a Matrix homeserver
+// that implements the client-server endpoints used by the matrix adapter.
+//
+// Tests inject room events as if other users had sent them and inspect the
+// events the bot sent:
+//
+//	hs := matrixtest.NewServer("@bot:test", "token")
+//	defer hs.Close()
+//	adapter, _ := matrix.New(types.MatrixConfig{Homeserver: hs.URL, AccessToken: "token"})
+//	...
+//	hs.Message("!room:test", "@alice:test", "ping")
+package matrixtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a room event stored by the server.
+type Event struct {
+	ID        string         `json:"event_id"`
+	Type      string         `json:"type"`
+	Sender    string         `json:"sender"`
+	RoomID    string         `json:"room_id"`
+	Timestamp int64          `json:"origin_server_ts"`
+	Redacts   string         `json:"redacts,omitempty"`
+	Content   map[string]any `json:"content"`
+}
+
+// Presence is the last presence set by the bot.
+type Presence struct {
+	Presence  string `json:"presence"`
+	StatusMsg string `json:"status_msg"`
+}
+
+// Server is a fake homeserver.
+type Server struct {
+	*httptest.Server
+
+	userID string
+	token  string
+
+	mu       sync.Mutex
+	events   []*Event
+	presence *Presence
+	nextID   int
+	wake     chan struct{}
+}
+
+// NewServer starts a homeserver that accepts token for userID.
+func NewServer(userID, token string) *Server {
+	s := &Server{userID: userID, token: token, wake: make(chan struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_matrix/client/v3/account/whoami", s.whoami)
+	mux.HandleFunc("GET /_matrix/client/v3/sync", s.sync)
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/send/{type}/{txn}", s.send)
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/redact/{event}/{txn}", s.redact)
+	mux.HandleFunc("PUT /_matrix/client/v3/presence/{user}/status", s.setPresence)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"errcode": "M_UNKNOWN_TOKEN", "error": "Invalid access token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Inject appends an event to a room's timeline and returns its ID.
+func (s *Server) Inject(roomID, sender, eventType string, content map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendLocked(&Event{Type: eventType, Sender: sender, RoomID: roomID, Content: content})
+}
+
+// Message injects a plain m.room.message.
+func (s *Server) Message(roomID, sender, body string) string {
+	return s.Inject(roomID, sender, "m.room.message", map[string]any{"msgtype": "m.text", "body": body})
+}
+
+// Edit injects an m.replace edit of eventID.
+func (s *Server) Edit(roomID, sender, eventID, body string) string {
+	return s.Inject(roomID, sender, "m.room.message", map[string]any{
+		"msgtype":       "m.text",
+		"body":          "* " + body,
+		"m.new_content": map[string]any{"msgtype": "m.text", "body": body},
+		"m.relates_to":  map[string]any{"rel_type": "m.replace", "event_id": eventID},
+	})
+}
+
+// Redact injects a redaction of eventID.
+func (s *Server) Redact(roomID, sender, eventID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendLocked(&Event{Type: "m.room.redaction", Sender: sender, RoomID: roomID, Redacts: eventID,
+		Content: map[string]any{"redacts": eventID}})
+}
+
+// React injects an m.reaction annotating eventID with key.
+func (s *Server) React(roomID, sender, eventID, key string) string {
+	return s.Inject(roomID, sender, "m.reaction", map[string]any{
+		"m.relates_to": map[string]any{"rel_type": "m.annotation", "event_id": eventID, "key": key},
+	})
+}
+
+// Sent returns every event the bot has sent, in order.
+func (s *Server) Sent() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sent []Event
+	for _, e := range s.events {
+		if e.Sender == s.userID {
+			sent = append(sent, *e)
+		}
+	}
+	return sent
+}
+
+// Presence returns the presence last set by the bot, or nil.
+func (s *Server) Presence() *Presence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.presence
+}
+
+func (s *Server) appendLocked(e *Event) string {
+	s.nextID++
+	e.ID = fmt.Sprintf("$%d", s.nextID)
+	e.Timestamp = time.Now().UnixMilli()
+	s.events = append(s.events, e)
+
+	close(s.wake)
+	s.wake = make(chan struct{})
+	return e.ID
+}
+
+func (s *Server) whoami(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"user_id": s.userID})
+}
+
+// sync long-polls for events after the "since" position, which is simply
+// the number of events already delivered. Like a real homeserver, an
+// initial sync without a position returns immediately.
+func (s *Server) sync(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	since, _ := strconv.Atoi(query.Get("since"))
+	timeout, _ := strconv.Atoi(query.Get("timeout"))
+
+	s.mu.Lock()
+	if query.Has("since") && len(s.events) <= since && timeout > 0 {
+		wake := s.wake
+		s.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-time.After(time.Duration(timeout) * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		s.mu.Lock()
+	}
+	if since > len(s.events) {
+		since = len(s.events)
+	}
+	pending := s.events[since:]
+	next := len(s.events)
+	s.mu.Unlock()
+
+	join := map[string]any{}
+	for _, e := range pending {
+		room, ok := join[e.RoomID].(map[string]any)
+		if !ok {
+			room = map[string]any{"timeline": map[string]any{"events": []*Event{}}}
+			join[e.RoomID] = room
+		}
+		timeline := room["timeline"].(map[string]any)
+		timeline["events"] = append(timeline["events"].([]*Event), e)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"next_batch": strconv.Itoa(next),
+		"rooms":      map[string]any{"join": join},
+	})
+}
+
+func (s *Server) send(w http.ResponseWriter, r *http.Request) {
+	var content map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&content); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errcode": "M_NOT_JSON", "error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	id := s.appendLocked(&Event{Type: r.PathValue("type"), Sender: s.userID, RoomID: r.PathValue("room"), Content: content})
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"event_id": id})
+}
+
+func (s *Server) redact(w http.ResponseWriter, r *http.Request) {
+	event := r.PathValue("event")
+
+	s.mu.Lock()
+	id := s.appendLocked(&Event{Type: "m.room.redaction", Sender: s.userID, RoomID: r.PathValue("room"), Redacts: event,
+		Content: map[string]any{"redacts": event}})
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"event_id": id})
+}
+
+func (s *Server) setPresence(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.PathValue("user"), s.userID) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"errcode": "M_FORBIDDEN", "error": "Cannot set presence of another user"})
+		return
+	}
+
+	var presence Presence
+	if err := json.NewDecoder(r.Body).Decode(&presence); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"errcode": "M_NOT_JSON", "error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.presence = &presence
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,209 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// syncTimeout is how long the homeserver may hold a /sync long poll.
+const syncTimeout = 30 * time.Second
+
+// Event is a Matrix room event as delivered by /sync. It is used as the
+// types.Event.Context of Matrix events.
+type Event struct {
+	ID        string          `json:"event_id"`
+	Type      string          `json:"type"`
+	Sender    string          `json:"sender"`
+	RoomID    string          `json:"room_id"`
+	Timestamp int64           `json:"origin_server_ts"`
+	Redacts   string          `json:"redacts,omitempty"`
+	Content   json.RawMessage `json:"content"`
+}
+
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []*Event `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+	} `json:"rooms"`
+}
+
+type eventRef struct {
+	EventID string `json:"event_id"`
+}
+
+type relatesTo struct {
+	RelType   string    `json:"rel_type,omitempty"`
+	EventID   string    `json:"event_id,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	InReplyTo *eventRef `json:"m.in_reply_to,omitempty"`
+}
+
+type messageContent struct {
+	MsgType       string          `json:"msgtype,omitempty"`
+	Body          string          `json:"body"`
+	Format        string          `json:"format,omitempty"`
+	FormattedBody string          `json:"formatted_body,omitempty"`
+	NewContent    *messageContent `json:"m.new_content,omitempty"`
+	RelatesTo     *relatesTo      `json:"m.relates_to,omitempty"`
+	Mentions      *mentions       `json:"m.mentions,omitempty"`
+	Redacts       string          `json:"redacts,omitempty"`
+}
+
+type mentions struct {
+	UserIDs []string `json:"user_ids,omitempty"`
+}
+
+// run is the sync loop. The first sync of a fresh connection only records
+// the position so that backlog is not replayed as new events.
+func (a *Adapter) run(ctx context.Context, since string) {
+	resumed := since != ""
+	announced := false
+
+	for {
+		resp, err := a.client.sync(ctx, since, syncTimeout)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return
+			}
+			log.Printf("Matrix sync failed: %v\n", err)
+			a.emitState(types.EventDisconnected)
+			return
+		}
+
+		if since != "" {
+			a.handleSync(resp)
+		}
+		since = resp.NextBatch
+		a.setSince(since)
+
+		if !announced {
+			a.ready.Fire()
+			if resumed {
+				a.emitState(types.EventResumed)
+			} else {
+				a.emitState(types.EventConnected)
+			}
+			announced = true
+		}
+	}
+}
+
+func (a *Adapter) handleSync(resp *syncResponse) {
+	for roomID, room := range resp.Rooms.Join {
+		for _, e := range room.Timeline.Events {
+			e.RoomID = roomID
+			a.handleEvent(e)
+		}
+	}
+}
+
+// handleEvent normalizes a single timeline event.
+func (a *Adapter) handleEvent(e *Event) {
+	emit := func(eventType types.EventType, data any) {
+		a.emit(types.Event{
+			Name:      string(eventType),
+			Type:      eventType,
+			Platform:  Name,
+			Bot:       e.Sender == a.userID,
+			Self:      e.Sender == a.userID,
+			Context:   e,
+			Session:   a,
+			Data:      data,
+			ChannelID: e.RoomID,
+		})
+	}
+
+	var content messageContent
+	if len(e.Content) > 0 {
+		if err := json.Unmarshal(e.Content, &content); err != nil {
+			log.Printf("Matrix event %s has invalid content: %v\n", e.ID, err)
+			return
+		}
+	}
+
+	switch e.Type {
+	case "m.room.message":
+		if content.RelatesTo != nil && content.RelatesTo.RelType == "m.replace" && content.NewContent != nil {
+			// Edits are events of their own; report the edited message.
+			msg := convertMessage(e, content.NewContent)
+			msg.ID = content.RelatesTo.EventID
+			msg.CreatedAt, msg.EditedAt = time.Time{}, msg.CreatedAt
+			emit(types.MessageUpdate, msg)
+			return
+		}
+		emit(types.MessageCreate, convertMessage(e, &content))
+
+	case "m.room.redaction":
+		// Room version 11 moved redacts into the content.
+		redacts := e.Redacts
+		if redacts == "" {
+			redacts = content.Redacts
+		}
+		emit(types.MessageDelete, types.MessageCallback{ID: redacts, ChannelID: e.RoomID})
+
+	case "m.reaction":
+		// Removing a reaction redacts it, which arrives as a MessageDelete.
+		if content.RelatesTo == nil || content.RelatesTo.RelType != "m.annotation" {
+			return
+		}
+		emit(types.ReactionAdd, types.ReactionCallback{
+			MessageID: content.RelatesTo.EventID,
+			ChannelID: e.RoomID,
+			User:      convertUser(e.Sender),
+			Emoji:     types.Emoji{Name: content.RelatesTo.Key},
+		})
+	}
+}
+
+// convertMessage normalizes a message event with the given content. Rooms
+// have no server, and whether a room is a DM is not known from the event.
+func convertMessage(e *Event, content *messageContent) types.MessageCallback {
+	msg := types.MessageCallback{
+		ID:        e.ID,
+		ChannelID: e.RoomID,
+		Content:   stripReplyFallback(content.Body),
+		Author:    convertUser(e.Sender),
+		CreatedAt: time.UnixMilli(e.Timestamp),
+	}
+	if content.RelatesTo != nil && content.RelatesTo.InReplyTo != nil {
+		msg.ReplyTo = content.RelatesTo.InReplyTo.EventID
+	}
+	if content.Mentions != nil {
+		msg.Mentions.Users = content.Mentions.UserIDs
+	}
+	return msg
+}
+
+// stripReplyFallback removes the quoted "> " lines that clients prepend to
+// the plain-text body of replies.
+func stripReplyFallback(body string) string {
+	if !strings.HasPrefix(body, "> ") {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, ">") {
+			return strings.TrimLeft(strings.Join(lines[i:], "\n"), "\n")
+		}
+	}
+	return body
+}
+
+// convertUser builds a user from a Matrix ID such as @alice:example.org.
+func convertUser(userID string) types.User {
+	username := strings.TrimPrefix(userID, "@")
+	if i := strings.IndexByte(username, ':'); i >= 0 {
+		username = username[:i]
+	}
+	return types.User{ID: userID, Username: username}
+}
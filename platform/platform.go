@@ -0,0 +1,209 @@
+// Package platform defines the adapter interface implemented by every chat
+// network WhiskerCat can talk to, along with a registry that adapters add
+// themselves to.
+//
+// Helpers in the root package never branch on platform names; they look the
+// adapter up by the Event.Platform string and call through this interface.
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Status describes the activity and presence a platform should show for the bot.
+type Status struct {
+	Activity types.ActivityType // What the bot is doing (e.g., Playing, Watching)
+	Name     string             // Name of the activity
+	Presence types.Presence     // Presence status (Online, Idle, DND, Invisible)
+	Raw      *string            // Optional platform-native status (e.g. Discord's "dnd")
+}
+
+// Platform is implemented by each chat network adapter.
+type Platform interface {
+	// Name returns the identifier used in types.Event.Platform (e.g. "Discord").
+	Name() string
+
+	// Connect opens the gateway connection.
+	Connect(ctx context.Context) error
+
+	// Disconnect closes the gateway connection.
+	Disconnect(ctx context.Context) error
+
+	// Ready returns a channel that is closed once the gateway has delivered
+	// its Ready event for the current connection.
+	Ready() <-chan struct{}
+
+	// HandleEvents installs the sink that receives every normalized event.
+	// It replaces any previously installed sink.
+	HandleEvents(sink func(types.Event))
+
+	// Send posts a new message to a channel.
+	Send(channelID string, msg types.MessageSend) (*types.SentMessage, error)
+
+	// Edit replaces the content of a previously sent message.
+	Edit(channelID, messageID string, msg types.MessageSend) (*types.SentMessage, error)
+
+	// Delete removes a message.
+	Delete(channelID, messageID string) error
+
+	// React adds an emoji reaction to a message.
+	React(channelID, messageID, emoji string) error
+
+	// SetPresence updates the bot's activity and presence.
+	SetPresence(status Status) error
+
+	// Respond replies to the event that produced it, editing the message with
+	// ID edit instead when edit is non-nil.
+	Respond(e types.Event, msg types.MessageSend, edit *string) (*types.SentMessage, error)
+}
+
+// Directory is implemented by platforms that can look up users and server
+// members. Lookups are answered from a cache fed by gateway events and fall
+// back to the platform's API.
+type Directory interface {
+	// User looks up a user by ID.
+	User(ctx context.Context, userID string) (types.User, error)
+
+	// Member looks up a user's membership of a server.
+	Member(ctx context.Context, serverID, userID string) (types.MemberCallback, error)
+
+	// CacheStats reports the combined activity of the user and member caches.
+	CacheStats() cache.Stats
+
+	// ConfigureCache replaces the user and member caches with empty ones
+	// bounded by config. It must be called before Connect.
+	ConfigureCache(config CacheConfig)
+}
+
+// CacheConfig bounds the user and member caches of a Directory.
+type CacheConfig struct {
+	Size int           // Entries per cache; 0 selects cache.DefaultSize
+	TTL  time.Duration // How long entries are kept; 0 selects cache.DefaultTTL
+}
+
+// Factory builds a Platform from the shared credentials. It returns a nil
+// Platform and no error when its section of config is absent or disabled.
+type Factory func(config *types.AuthConfig) (Platform, error)
+
+// ErrNotConfigured is returned when a caller targets a registered platform
+// that the bot was not configured for.
+var ErrNotConfigured = errors.New("platform not configured")
+
+// registration is a registered factory and the name it was registered as.
+type registration struct {
+	name    string
+	factory Factory
+}
+
+var (
+	registry   = make(map[string]registration) // keyed by lower-cased name
+	registryMu sync.RWMutex
+)
+
+// Register makes a platform factory available under name.
+// It is intended to be called from an adapter's init function and panics if
+// the name is registered twice, in any case.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("platform: Register factory is nil for " + name)
+	}
+	key := types.PlatformKey(name)
+	if _, exists := registry[key]; exists {
+		panic("platform: Register called twice for " + name)
+	}
+	registry[key] = registration{name: name, factory: factory}
+}
+
+// Lookup returns the factory registered under name, which is matched
+// case-insensitively like the platform names in references.
+func Lookup(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[types.PlatformKey(name)]
+	return r.factory, ok
+}
+
+// Names returns the sorted names of all registered platforms.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for _, r := range registry {
+		names = append(names, r.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open builds every registered platform that config enables.
+func Open(config *types.AuthConfig) (map[string]Platform, error) {
+	platforms := make(map[string]Platform)
+	for _, name := range Names() {
+		factory, _ := Lookup(name)
+		p, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if p != nil {
+			platforms[name] = p
+		}
+	}
+	return platforms, nil
+}
+
+// ReadySignal is a resettable broadcast that adapters use to implement
+// Platform.Ready. The zero value is ready to use.
+type ReadySignal struct {
+	mu    sync.Mutex
+	ch    chan struct{}
+	fired bool
+}
+
+// Reset arms the signal for a new connection.
+func (r *ReadySignal) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ch == nil || r.fired {
+		r.ch = make(chan struct{})
+		r.fired = false
+	}
+}
+
+// Fire closes the current channel. Extra calls are ignored until Reset.
+func (r *ReadySignal) Fire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ch == nil {
+		r.ch = make(chan struct{})
+	}
+	if !r.fired {
+		close(r.ch)
+		r.fired = true
+	}
+}
+
+// Done returns a channel that is closed once Fire is called.
+func (r *ReadySignal) Done() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ch == nil {
+		r.ch = make(chan struct{})
+	}
+	return r.ch
+}
@@ -0,0 +1,171 @@
+package revolt
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/oklog/ulid/v2"
+	"github.com/sentinelb51/revoltgo"
+)
+
+// Channel and role mentions are written <#id> and <%id> in message content.
+var (
+	channelMention = regexp.MustCompile(`<#([0-9A-HJKMNP-TV-Z]{26})>`)
+	roleMention    = regexp.MustCompile(`<%([0-9A-HJKMNP-TV-Z]{26})>`)
+)
+
+func convertUser(user *revoltgo.User) types.User {
+	if user == nil {
+		return types.User{}
+	}
+	u := types.User{
+		ID:       user.ID,
+		Username: user.Username,
+	}
+	if user.Avatar != nil {
+		u.Avatar = user.Avatar.URL("128")
+	}
+	return u
+}
+
+func convertServer(server *revoltgo.Server) types.Server {
+	if server == nil {
+		return types.Server{}
+	}
+	s := types.Server{
+		ID:      server.ID,
+		Name:    server.Name,
+		OwnerID: server.Owner,
+	}
+	if server.Icon != nil {
+		s.Icon = server.Icon.URL("128")
+	}
+	for id, role := range server.Roles {
+		s.Roles = append(s.Roles, convertRole(server.ID, id, role))
+	}
+	slices.SortFunc(s.Roles, func(a, b types.Role) int { return a.Position - b.Position })
+	return s
+}
+
+// withChannels adds the channels among channels that belong to server.
+func withChannels(server types.Server, channels []*revoltgo.Channel) types.Server {
+	for _, channel := range channels {
+		if channel.Server == server.ID {
+			server.Channels = append(server.Channels, convertChannel(channel))
+		}
+	}
+	return server
+}
+
+func convertRole(serverID, roleID string, role *revoltgo.ServerRole) types.Role {
+	r := types.Role{ID: roleID, ServerID: serverID}
+	if role == nil {
+		return r
+	}
+	r.Name = role.Name
+	r.Position = role.Rank
+	r.Hoist = role.Hoist
+	if role.Permissions != nil {
+		r.Permissions = int64(role.Permissions.Allow)
+	}
+	// Colours may be any CSS colour; only plain hex colours are converted.
+	if hex, ok := strings.CutPrefix(role.Colour, "#"); ok && len(hex) == 6 {
+		if color, err := strconv.ParseInt(hex, 16, 32); err == nil {
+			r.Color = int(color)
+		}
+	}
+	return r
+}
+
+func convertChannel(channel *revoltgo.Channel) types.Channel {
+	c := types.Channel{
+		ID:       channel.ID,
+		ServerID: channel.Server,
+		Name:     channel.Name,
+		Topic:    channel.Description,
+		NSFW:     channel.NSFW,
+	}
+	switch channel.ChannelType {
+	case revoltgo.ChannelTypeText:
+		c.Type = types.ChannelText
+	case revoltgo.ChannelTypeVoice:
+		c.Type = types.ChannelVoice
+	case revoltgo.ChannelTypeDM, revoltgo.ChannelTypeSavedMessages:
+		c.Type = types.ChannelDM
+	case revoltgo.ChannelTypeGroup:
+		c.Type = types.ChannelGroup
+	default:
+		c.Type = types.ChannelOther
+	}
+	return c
+}
+
+// idTime returns the creation time encoded in a Revolt ULID, or the zero
+// time if id is not a ULID.
+func idTime(id string) time.Time {
+	parsed, err := ulid.Parse(id)
+	if err != nil {
+		return time.Time{}
+	}
+	return ulid.Time(parsed.Time())
+}
+
+// mentioned returns the unique IDs pattern captures in content.
+func mentioned(pattern *regexp.Regexp, content string) []string {
+	var ids []string
+	for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+		if !slices.Contains(ids, match[1]) {
+			ids = append(ids, match[1])
+		}
+	}
+	return ids
+}
+
+func convertEmbed(embed *types.Embed) *revoltgo.MessageEmbed {
+	if embed == nil {
+		return nil
+	}
+
+	description := embed.Description
+
+	// Append fields as Markdown
+	if embed.Fields != nil && len(*embed.Fields) > 0 {
+		var fieldMarkdown strings.Builder
+		fieldMarkdown.WriteString("\n\n")
+		for _, f := range *embed.Fields {
+			fieldMarkdown.WriteString(fmt.Sprintf("**%s**\n%s\n\n", f.Name, f.Value))
+		}
+		description += fieldMarkdown.String()
+	}
+
+	em := &revoltgo.MessageEmbed{
+		Title:       embed.Title,
+		Description: description,
+		Colour:      fmt.Sprintf("#%06X", embed.Color),
+	}
+
+	if embed.URL != nil {
+		em.URL = *embed.URL
+	}
+	if embed.PhotoURL != nil {
+		em.Image = &revoltgo.MessageEmbedImage{URL: *embed.PhotoURL}
+	}
+
+	return em
+}
+
+// maxMasqueradeName is the longest masquerade name Revolt accepts.
+const maxMasqueradeName = 32
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
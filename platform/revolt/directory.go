@@ -0,0 +1,121 @@
+package revolt
+
+import (
+	"context"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/sentinelb51/revoltgo"
+)
+
+// memberKey identifies a membership in the member cache.
+type memberKey struct {
+	serverID, userID string
+}
+
+// User implements platform.Directory.
+func (a *Adapter) User(ctx context.Context, userID string) (types.User, error) {
+	if err := ctx.Err(); err != nil {
+		return types.User{}, err
+	}
+	user, err := a.loadUser(userID)
+	if err != nil {
+		return types.User{}, err
+	}
+	return convertUser(user), nil
+}
+
+// Member implements platform.Directory.
+func (a *Adapter) Member(ctx context.Context, serverID, userID string) (types.MemberCallback, error) {
+	if err := ctx.Err(); err != nil {
+		return types.MemberCallback{}, err
+	}
+	return a.members.GetOrLoad(memberKey{serverID, userID}, func() (types.MemberCallback, error) {
+		member := a.stateMember(serverID, userID)
+		if member == nil {
+			var err error
+			if member, err = a.session.ServerMember(serverID, userID); err != nil {
+				return types.MemberCallback{}, err
+			}
+		}
+		user, err := a.loadUser(userID)
+		if err != nil {
+			return types.MemberCallback{}, err
+		}
+		return convertMember(serverID, user, member), nil
+	})
+}
+
+// CacheStats implements platform.Directory.
+func (a *Adapter) CacheStats() cache.Stats {
+	return a.users.Stats().Add(a.members.Stats())
+}
+
+// ConfigureCache implements platform.Directory.
+func (a *Adapter) ConfigureCache(config platform.CacheConfig) {
+	a.users = cache.New[string, *revoltgo.User](config.Size, config.TTL)
+	a.members = cache.New[memberKey, types.MemberCallback](config.Size, config.TTL)
+}
+
+// loadUser returns a user from the cache, then the session state, and only
+// falls back to the REST API for users the gateway has not told us about.
+func (a *Adapter) loadUser(userID string) (*revoltgo.User, error) {
+	return a.users.GetOrLoad(userID, func() (*revoltgo.User, error) {
+		if a.session.State != nil {
+			if user := a.session.State.User(userID); user != nil {
+				return user, nil
+			}
+		}
+		return a.session.User(userID)
+	})
+}
+
+// lookupUser returns a user for event normalization from the cache or the
+// session state only. It runs on the gateway goroutine, so a miss returns
+// nil instead of blocking on the REST API; callers keep the user ID.
+func (a *Adapter) lookupUser(userID string) *revoltgo.User {
+	if userID == "" {
+		return nil
+	}
+	if user, ok := a.users.Get(userID); ok {
+		return user
+	}
+	if a.session.State != nil {
+		if user := a.session.State.User(userID); user != nil {
+			a.users.Set(userID, user)
+			return user
+		}
+	}
+	return nil
+}
+
+func (a *Adapter) stateMember(serverID, userID string) *revoltgo.ServerMember {
+	if a.session.State == nil {
+		return nil
+	}
+	return a.session.State.Member(userID, serverID)
+}
+
+// member describes a membership for a member event, keeping at least the
+// user ID when the lookups fail. Members that just left are no longer in
+// the session state, so only their user is known.
+func (a *Adapter) member(serverID, userID string) types.MemberCallback {
+	m := convertMember(serverID, a.lookupUser(userID), a.stateMember(serverID, userID))
+	if m.User.ID == "" {
+		m.User.ID = userID
+	}
+	return m
+}
+
+func convertMember(serverID string, user *revoltgo.User, member *revoltgo.ServerMember) types.MemberCallback {
+	m := types.MemberCallback{
+		User:     convertUser(user),
+		ServerID: serverID,
+	}
+	if member != nil {
+		m.JoinedAt = member.JoinedAt
+		m.Roles = member.Roles
+	}
+	return m
+}
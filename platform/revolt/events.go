@@ -0,0 +1,258 @@
+package revolt
+
+import (
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/oklog/ulid/v2"
+	"github.com/sentinelb51/revoltgo"
+)
+
+// registerEvents wires revoltgo handlers that normalize gateway events and
+// forward them to the installed sink.
+func (a *Adapter) registerEvents() {
+	event := func(eventType types.EventType, context any, session *revoltgo.Session, bot bool, channelID, serverID string, data any) types.Event {
+		return types.Event{
+			Name:      string(eventType),
+			Type:      eventType,
+			Platform:  Name,
+			Bot:       bot,
+			Context:   context,
+			Session:   session,
+			Data:      data,
+			ChannelID: channelID,
+			ServerID:  serverID,
+		}
+	}
+	emit := func(eventType types.EventType, context any, session *revoltgo.Session, bot bool, channelID, serverID string, data any) {
+		a.emit(event(eventType, context, session, bot, channelID, serverID, data))
+	}
+
+	// Like Discord's GuildCreate burst, every server the bot is in is
+	// announced after connecting.
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventReady) {
+		for _, user := range e.Users {
+			a.users.Set(user.ID, user)
+		}
+		a.ready.Fire()
+		emit(types.EventConnected, e, s, false, "", "", nil)
+		for _, server := range e.Servers {
+			emit(types.EventServerCreate, e, s, false, "", server.ID, withChannels(convertServer(server), e.Channels))
+		}
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventMessage) {
+		user := a.lookupUser(e.Author)
+		msg := a.convertMessage(&e.Message, user)
+		evt := event(types.MessageCreate, e, s, isBot(user), msg.ChannelID, msg.ServerID, msg)
+		evt.Self = a.isSelf(e.Author)
+		a.emit(evt)
+	})
+
+	// revoltgo only delivers MessageUpdate through the abstract update event.
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.AbstractEventUpdate) {
+		if e.Type != "MessageUpdate" {
+			return
+		}
+		upd := e.EventMessageUpdate()
+		// Updates are partial, but always name the message and channel.
+		upd.Data.ID, upd.Data.Channel = upd.ID, upd.Channel
+		user := a.lookupUser(upd.Data.Author)
+		msg := a.convertMessage(&upd.Data, user)
+		evt := event(types.MessageUpdate, upd, s, isBot(user), msg.ChannelID, msg.ServerID, msg)
+		evt.Self = a.isSelf(upd.Data.Author)
+		a.emit(evt)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventMessageDelete) {
+		msg := types.MessageCallback{ID: e.ID, ChannelID: e.Channel, ServerID: a.serverOf(e.Channel), DM: a.isDM(e.Channel)}
+		emit(types.MessageDelete, e, s, false, e.Channel, msg.ServerID, msg)
+	})
+
+	// revoltgo drops BulkMessageDelete without calling any handler, so bulk
+	// deletions are not reported.
+
+	// revoltgo has no handler type for MessageRemoveReaction, so reactions
+	// cleared by moderators are not reported.
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventMessageReact) {
+		reaction := a.convertReaction(e)
+		evt := event(types.ReactionAdd, e, s, false, reaction.ChannelID, reaction.ServerID, reaction)
+		evt.Self = a.isSelf(e.UserID)
+		a.emit(evt)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventMessageUnreact) {
+		reaction := a.convertReaction(&e.EventMessageReact)
+		evt := event(types.ReactionRemove, e, s, false, reaction.ChannelID, reaction.ServerID, reaction)
+		evt.Self = a.isSelf(e.UserID)
+		a.emit(evt)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventChannelStartTyping) {
+		emit(types.EventTypingStart, e, s, false, e.ID, a.serverOf(e.ID), nil)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventChannelCreate) {
+		emit(types.EventChannelCreate, e, s, false, e.ID, e.Server, convertChannel(e.Channel))
+	})
+
+	// Updates only carry the changed fields; the session state has already
+	// applied them when the handler runs.
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventChannelUpdate) {
+		channel := types.Channel{ID: e.ID}
+		if s.State != nil {
+			if current := s.State.Channel(e.ID); current != nil {
+				channel = convertChannel(current)
+			}
+		}
+		emit(types.EventChannelUpdate, e, s, false, e.ID, channel.ServerID, channel)
+	})
+
+	// Deleted channels are gone from the session state by now.
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventChannelDelete) {
+		emit(types.EventChannelDelete, e, s, false, e.ID, "", types.Channel{ID: e.ID})
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventUserUpdate) {
+		user := types.User{ID: e.ID}
+		if s.State != nil {
+			if current := s.State.User(e.ID); current != nil {
+				a.users.Set(e.ID, current)
+				user = convertUser(current)
+			}
+		} else {
+			// Without state tracking the cached copy is stale.
+			a.users.Delete(e.ID)
+		}
+		emit(types.EventUserUpdate, e, s, false, "", "", user)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerCreate) {
+		server := convertServer(e.Server)
+		server.ID = e.ID
+		emit(types.EventServerCreate, e, s, false, "", e.ID, withChannels(server, e.Channels))
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerUpdate) {
+		server := types.Server{ID: e.ID}
+		if s.State != nil {
+			if current := s.State.Server(e.ID); current != nil {
+				server = convertServer(current)
+			}
+		}
+		emit(types.EventServerUpdate, e, s, false, "", e.ID, server)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerDelete) {
+		emit(types.EventServerDelete, e, s, false, "", e.ID, types.Server{ID: e.ID})
+	})
+
+	// Revolt has no separate event for new roles; they arrive as updates.
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerRoleUpdate) {
+		role := e.Data
+		if s.State != nil {
+			if current := s.State.Role(e.ID, e.RoleID); current != nil {
+				role = current
+			}
+		}
+		emit(types.EventRoleUpdate, e, s, false, "", e.ID, convertRole(e.ID, e.RoleID, role))
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerRoleDelete) {
+		emit(types.EventRoleDelete, e, s, false, "", e.ID, types.Role{ID: e.RoleID, ServerID: e.ID})
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerMemberUpdate) {
+		member := a.member(e.ID.Server, e.ID.User)
+		a.members.Set(memberKey{e.ID.Server, e.ID.User}, member)
+		emit(types.EventMemberUpdate, e, s, false, "", e.ID.Server, member)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerMemberJoin) {
+		member := a.member(e.ID, e.User)
+		a.members.Set(memberKey{e.ID, e.User}, member)
+		emit(types.EventMemberJoin, e, s, false, "", e.ID, member)
+	})
+
+	a.session.AddHandler(func(s *revoltgo.Session, e *revoltgo.EventServerMemberLeave) {
+		member := a.member(e.ID, e.User)
+		a.members.Delete(memberKey{e.ID, e.User})
+		emit(types.EventMemberLeave, e, s, false, "", e.ID, member)
+	})
+}
+
+// convertMessage normalizes a message sent by author.
+func (a *Adapter) convertMessage(m *revoltgo.Message, author *revoltgo.User) types.MessageCallback {
+	msg := types.MessageCallback{
+		ID:        m.ID,
+		ChannelID: m.Channel,
+		ServerID:  a.serverOf(m.Channel),
+		Content:   m.Content,
+		Author:    convertUser(author),
+		CreatedAt: idTime(m.ID),
+		EditedAt:  m.Edited,
+		Mentions: types.Mentions{
+			Users:    m.Mentions,
+			Roles:    mentioned(roleMention, m.Content),
+			Channels: mentioned(channelMention, m.Content),
+		},
+		DM: a.isDM(m.Channel),
+	}
+	if msg.Author.ID == "" {
+		msg.Author.ID = m.Author
+	}
+	if len(m.Replies) > 0 {
+		msg.ReplyTo = m.Replies[0]
+	}
+	return msg
+}
+
+// convertReaction normalizes a reaction. Custom emoji are referenced by
+// ID; their name is filled in when the session state knows the emoji.
+func (a *Adapter) convertReaction(e *revoltgo.EventMessageReact) types.ReactionCallback {
+	reaction := types.ReactionCallback{
+		MessageID: e.ID,
+		ChannelID: e.ChannelID,
+		ServerID:  a.serverOf(e.ChannelID),
+		User:      types.User{ID: e.UserID},
+		Emoji:     types.Emoji{Name: e.EmojiID},
+	}
+	if a.session.State != nil {
+		if user := a.session.State.User(e.UserID); user != nil {
+			reaction.User = convertUser(user)
+		}
+	}
+	if _, err := ulid.Parse(e.EmojiID); err == nil {
+		reaction.Emoji = types.Emoji{ID: e.EmojiID}
+		if a.session.State != nil {
+			if emoji := a.session.State.Emoji(e.EmojiID); emoji != nil {
+				reaction.Emoji.Name = emoji.Name
+				reaction.Emoji.Animated = emoji.Animated
+			}
+		}
+	}
+	return reaction
+}
+
+// isDM reports whether a channel is a direct message or group, as far as
+// the session state knows.
+func (a *Adapter) isDM(channelID string) bool {
+	if a.session.State == nil {
+		return false
+	}
+	channel := a.session.State.Channel(channelID)
+	return channel != nil && channel.Server == ""
+}
+
+// serverOf resolves the server a channel belongs to from the session state.
+func (a *Adapter) serverOf(channelID string) string {
+	if channelID == "" || a.session.State == nil {
+		return ""
+	}
+	if channel := a.session.State.Channel(channelID); channel != nil {
+		return channel.Server
+	}
+	return ""
+}
+
+func isBot(user *revoltgo.User) bool {
+	return user != nil && user.Bot != nil
+}
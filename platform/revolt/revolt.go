@@ -0,0 +1,229 @@
+// Package revolt adapts revoltgo to the platform.Platform interface.
+package revolt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/sentinelb51/revoltgo"
+)
+
+// Name is the value used in types.Event.Platform for Revolt events.
+const Name = "Revolt"
+
+func init() {
+	platform.Register(Name, func(config *types.AuthConfig) (platform.Platform, error) {
+		if !config.Revolt.Enabled() {
+			return nil, nil
+		}
+		return New(*config.Revolt), nil
+	})
+}
+
+// Adapter implements platform.Platform on top of a revoltgo session.
+type Adapter struct {
+	session *revoltgo.Session
+
+	ready platform.ReadySignal
+
+	watchMu   sync.Mutex
+	stopWatch chan struct{}
+
+	mu   sync.RWMutex
+	sink func(types.Event)
+
+	users   *cache.Cache[string, *revoltgo.User]
+	members *cache.Cache[memberKey, types.MemberCallback]
+}
+
+// New creates a Revolt adapter and registers its gateway handlers.
+func New(config types.RevoltConfig) *Adapter {
+	session := revoltgo.New(config.Token)
+
+	// Reconnects are driven by whoever owns the adapter (the bot's
+	// supervisor); revoltgo's own loop panics when a redial fails.
+	session.ShouldReconnect = false
+
+	a := &Adapter{
+		session: session,
+	}
+	a.ConfigureCache(platform.CacheConfig{})
+	a.registerEvents()
+	return a
+}
+
+// Session returns the underlying revoltgo session.
+func (a *Adapter) Session() *revoltgo.Session { return a.session }
+
+// Name implements platform.Platform.
+func (a *Adapter) Name() string { return Name }
+
+// Connect implements platform.Platform.
+func (a *Adapter) Connect(_ context.Context) (err error) {
+	// revoltgo panics instead of returning an error when the websocket dial fails.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	a.ready.Reset()
+	if err = a.session.Open(); err != nil {
+		return err
+	}
+
+	a.watchMu.Lock()
+	a.stopWatch = make(chan struct{})
+	go a.watch(a.stopWatch)
+	a.watchMu.Unlock()
+	return nil
+}
+
+// Disconnect implements platform.Platform.
+func (a *Adapter) Disconnect(_ context.Context) error {
+	a.watchMu.Lock()
+	if a.stopWatch != nil {
+		close(a.stopWatch)
+		a.stopWatch = nil
+	}
+	a.watchMu.Unlock()
+
+	if a.session.Socket == nil {
+		return nil
+	}
+	err := a.session.Close()
+	a.emitState(types.EventDisconnected)
+	return err
+}
+
+// watch polls the session for a dropped connection, because revoltgo has no
+// disconnect event, and reports it as EventDisconnected.
+func (a *Adapter) watch(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !a.session.Connected {
+				a.emitState(types.EventDisconnected)
+				return
+			}
+		}
+	}
+}
+
+func (a *Adapter) emitState(eventType types.EventType) {
+	a.emit(types.Event{
+		Name:     string(eventType),
+		Type:     eventType,
+		Platform: Name,
+		Session:  a.session,
+	})
+}
+
+// Ready implements platform.Platform.
+func (a *Adapter) Ready() <-chan struct{} { return a.ready.Done() }
+
+// HandleEvents implements platform.Platform.
+func (a *Adapter) HandleEvents(sink func(types.Event)) {
+	a.mu.Lock()
+	a.sink = sink
+	a.mu.Unlock()
+}
+
+func (a *Adapter) emit(evt types.Event) {
+	a.mu.RLock()
+	sink := a.sink
+	a.mu.RUnlock()
+
+	if sink != nil {
+		sink(evt)
+	}
+}
+
+// Send implements platform.Platform.
+// Masqueraded messages need the Masquerade permission.
+func (a *Adapter) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	send := revoltgo.MessageSend{Content: msg.Content}
+	if msg.ReplyTo != "" {
+		send.Replies = []*revoltgo.MessageReplies{{ID: msg.ReplyTo}}
+	}
+	if msg.Masquerade != nil {
+		send.Masquerade = &revoltgo.MessageMasquerade{Name: truncate(msg.Masquerade.Name, maxMasqueradeName), Avatar: msg.Masquerade.Avatar}
+	}
+	if msg.Embed != nil {
+		send.Embeds = []*revoltgo.MessageEmbed{convertEmbed(msg.Embed)}
+	}
+	return sent(a.session.ChannelMessageSend(channelID, send))
+}
+
+// Edit implements platform.Platform. A message keeps the Masquerade it was
+// sent with.
+func (a *Adapter) Edit(channelID, messageID string, msg types.MessageSend) (*types.SentMessage, error) {
+	edit := revoltgo.MessageEditData{Content: msg.Content}
+	if msg.Embed != nil {
+		edit.Embeds = []*revoltgo.MessageEmbed{convertEmbed(msg.Embed)}
+	}
+	return sent(a.session.ChannelMessageEdit(channelID, messageID, edit))
+}
+
+// Delete implements platform.Platform.
+func (a *Adapter) Delete(channelID, messageID string) error {
+	return a.session.ChannelMessageDelete(channelID, messageID)
+}
+
+// React implements platform.Platform.
+func (a *Adapter) React(channelID, messageID, emoji string) error {
+	return a.session.ChannelMessageReactionCreate(channelID, messageID, emoji)
+}
+
+// SetPresence implements platform.Platform.
+func (a *Adapter) SetPresence(status platform.Status) error {
+	self := a.session.State.Self()
+	if self == nil {
+		return fmt.Errorf("Self() is nil")
+	}
+
+	_, err := a.session.UserEdit(self.ID, revoltgo.UserEditData{
+		Status: &revoltgo.UserStatus{
+			Text:     status.Name,
+			Presence: revoltgo.UserStatusPresence(status.Presence),
+		},
+	})
+	return err
+}
+
+// Respond implements platform.Platform.
+func (a *Adapter) Respond(e types.Event, msg types.MessageSend, edit *string) (*types.SentMessage, error) {
+	if e.ChannelID == "" {
+		return nil, fmt.Errorf("unsupported Revolt context %T", e.Context)
+	}
+	if edit != nil {
+		return a.Edit(e.ChannelID, *edit, msg)
+	}
+	return a.Send(e.ChannelID, msg)
+}
+
+// isSelf reports whether userID is the bot's own account.
+func (a *Adapter) isSelf(userID string) bool {
+	if a.session.State == nil {
+		return false
+	}
+	self := a.session.State.Self()
+	return self != nil && self.ID == userID
+}
+
+func sent(m *revoltgo.Message, err error) (*types.SentMessage, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &types.SentMessage{ID: m.ID, ChannelID: m.Channel, Platform: Name, Raw: m}, nil
+}
@@ -0,0 +1,162 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultAPIURL is the public Bot API server.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// client is a minimal Bot API client.
+type client struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// Error is returned when the Bot API answers with ok=false.
+type Error struct {
+	Code        int    `json:"error_code"`
+	Description string `json:"description"`
+	RetryAfter  int    `json:"-"` // Seconds to wait before retrying, set on 429 errors
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("telegram: %d: %s", e.Code, e.Description)
+}
+
+type response struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// call invokes a Bot API method with a JSON body and decodes the result into out.
+func (c *client) call(ctx context.Context, method string, params, out any) error {
+	if params == nil {
+		params = struct{}{}
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	u := c.apiURL + "/bot" + c.token + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The request URL contains the token, keep it out of the error.
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("telegram: %s: %d %s", method, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if !body.OK {
+		apiErr := &Error{Code: body.ErrorCode, Description: body.Description}
+		if body.Parameters != nil {
+			apiErr.RetryAfter = body.Parameters.RetryAfter
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body.Result, out)
+}
+
+func (c *client) getMe(ctx context.Context) (*User, error) {
+	var me User
+	if err := c.call(ctx, "getMe", nil, &me); err != nil {
+		return nil, err
+	}
+	return &me, nil
+}
+
+func (c *client) getUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]*Update, error) {
+	var updates []*Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message", "edited_message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+type replyParameters struct {
+	MessageID                int  `json:"message_id"`
+	AllowSendingWithoutReply bool `json:"allow_sending_without_reply,omitempty"`
+}
+
+type sendMessage struct {
+	ChatID          string           `json:"chat_id"`
+	MessageID       int              `json:"message_id,omitempty"`
+	Text            string           `json:"text"`
+	ParseMode       string           `json:"parse_mode,omitempty"`
+	ReplyParameters *replyParameters `json:"reply_parameters,omitempty"`
+}
+
+func (c *client) sendMessage(ctx context.Context, params *sendMessage) (*Message, error) {
+	var msg Message
+	if err := c.call(ctx, "sendMessage", params, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// editMessageText returns the edited message. Telegram answers with true
+// instead of a message for inline messages, which the adapter never edits.
+func (c *client) editMessageText(ctx context.Context, params *sendMessage) (*Message, error) {
+	var msg Message
+	if err := c.call(ctx, "editMessageText", params, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (c *client) deleteMessage(ctx context.Context, chatID string, messageID int) error {
+	return c.call(ctx, "deleteMessage", map[string]any{"chat_id": chatID, "message_id": messageID}, nil)
+}
+
+func (c *client) setMessageReaction(ctx context.Context, chatID string, messageID int, emoji string) error {
+	return c.call(ctx, "setMessageReaction", map[string]any{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"reaction":   []map[string]string{{"type": "emoji", "emoji": emoji}},
+	}, nil)
+}
+
+func (c *client) answerCallbackQuery(ctx context.Context, id string) error {
+	return c.call(ctx, "answerCallbackQuery", map[string]any{"callback_query_id": id}, nil)
+}
+
+// BotCommand is an entry of the command menu set with setMyCommands.
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+func (c *client) setMyCommands(ctx context.Context, commands []BotCommand) error {
+	return c.call(ctx, "setMyCommands", map[string]any{"commands": commands}, nil)
+}
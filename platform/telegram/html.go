@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// renderMessage converts an outgoing message into text for parse_mode
+// HTML. Telegram has no embeds, so an embed is rendered as a blockquote
+// below the content.
+func renderMessage(msg types.MessageSend) string {
+	var b strings.Builder
+	b.WriteString(html.EscapeString(msg.Content))
+
+	embed := msg.Embed
+	if embed == nil {
+		return b.String()
+	}
+
+	var lines []string
+	if embed.Title != "" {
+		title := "<b>" + html.EscapeString(embed.Title) + "</b>"
+		if embed.URL != nil {
+			title = `<a href="` + html.EscapeString(*embed.URL) + `">` + title + "</a>"
+		}
+		lines = append(lines, title)
+	}
+	if embed.Description != "" {
+		lines = append(lines, html.EscapeString(embed.Description))
+	}
+	if embed.Fields != nil {
+		for _, f := range *embed.Fields {
+			lines = append(lines, "<b>"+html.EscapeString(f.Name)+"</b>\n"+html.EscapeString(f.Value))
+		}
+	}
+	if embed.PhotoURL != nil {
+		lines = append(lines, `<a href="`+html.EscapeString(*embed.PhotoURL)+`">`+html.EscapeString(*embed.PhotoURL)+"</a>")
+	}
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		lines = append(lines, "<i>"+html.EscapeString(embed.Footer.Text)+"</i>")
+	}
+
+	if len(lines) == 0 {
+		return b.String()
+	}
+	if msg.Content != "" {
+		b.WriteString("\n\n")
+	}
+	b.WriteString("<blockquote>" + strings.Join(lines, "\n\n") + "</blockquote>")
+	return b.String()
+}
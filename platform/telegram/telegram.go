@@ -0,0 +1,291 @@
+// Package telegram adapts the Telegram Bot API to the platform.Platform
+// interface. Chats are treated as channels; updates are received by long
+// polling getUpdates.
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Name is the value used in types.Event.Platform for Telegram events.
+const Name = "Telegram"
+
+func init() {
+	platform.Register(Name, func(config *types.AuthConfig) (platform.Platform, error) {
+		if !config.Telegram.Enabled() {
+			return nil, nil
+		}
+		return New(*config.Telegram)
+	})
+}
+
+// Adapter implements platform.Platform on top of the Bot API.
+type Adapter struct {
+	client *client
+
+	ready platform.ReadySignal
+
+	runMu  sync.Mutex
+	cancel context.CancelFunc
+	runID  int
+
+	mu       sync.RWMutex
+	sink     func(types.Event)
+	me       *User
+	offset   int64
+	commands map[string]bool // Names of the commands set with SetCommands
+}
+
+// New creates a Telegram adapter. Nothing is requested until Connect.
+func New(config types.TelegramConfig) (*Adapter, error) {
+	if config.Token == "" {
+		return nil, errors.New("no token provided")
+	}
+	apiURL := config.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	return &Adapter{
+		client: &client{
+			apiURL: strings.TrimRight(apiURL, "/"),
+			token:  config.Token,
+			http:   &http.Client{Timeout: pollTimeout + 10*time.Second},
+		},
+	}, nil
+}
+
+// Name implements platform.Platform.
+func (a *Adapter) Name() string { return Name }
+
+// Me returns the bot's own user, or nil before the first Connect.
+func (a *Adapter) Me() *User {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.me
+}
+
+func (a *Adapter) username() string {
+	if me := a.Me(); me != nil {
+		return me.Username
+	}
+	return ""
+}
+
+// Connect implements platform.Platform. It verifies the token and starts
+// the getUpdates loop; a reconnect continues after the last seen update.
+func (a *Adapter) Connect(ctx context.Context) error {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+
+	if a.cancel != nil {
+		return errors.New("already connected")
+	}
+
+	me, err := a.client.getMe(ctx)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.me = me
+	offset := a.offset
+	a.mu.Unlock()
+
+	a.ready.Reset()
+	runCtx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.runID++
+	runID := a.runID
+
+	go func() {
+		a.run(runCtx, offset)
+
+		// Let the supervisor reconnect once the loop has given up.
+		a.runMu.Lock()
+		if a.runID == runID {
+			a.cancel = nil
+		}
+		a.runMu.Unlock()
+	}()
+	return nil
+}
+
+// Disconnect implements platform.Platform.
+func (a *Adapter) Disconnect(_ context.Context) error {
+	a.runMu.Lock()
+	cancel := a.cancel
+	a.cancel = nil
+	a.runMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		a.emitState(types.EventDisconnected)
+	}
+	return nil
+}
+
+// Ready implements platform.Platform.
+func (a *Adapter) Ready() <-chan struct{} { return a.ready.Done() }
+
+// HandleEvents implements platform.Platform.
+func (a *Adapter) HandleEvents(sink func(types.Event)) {
+	a.mu.Lock()
+	a.sink = sink
+	a.mu.Unlock()
+}
+
+func (a *Adapter) emit(evt types.Event) {
+	a.mu.RLock()
+	sink := a.sink
+	a.mu.RUnlock()
+
+	if sink != nil {
+		sink(evt)
+	}
+}
+
+func (a *Adapter) emitState(eventType types.EventType) {
+	a.emit(types.Event{
+		Name:     string(eventType),
+		Type:     eventType,
+		Platform: Name,
+		Session:  a,
+	})
+}
+
+func (a *Adapter) setOffset(offset int64) {
+	a.mu.Lock()
+	a.offset = offset
+	a.mu.Unlock()
+}
+
+// SetCommands publishes cmds as the bot's command menu and makes messages
+// such as "/ping" arrive as InteractionCreate events, so the definitions
+// passed to EnsureSlashCommands can be reused as is. Their arguments are
+// left unparsed in InteractionCallback.Args. Telegram only accepts
+// lowercase names of up to 32 letters, digits and underscores.
+func (a *Adapter) SetCommands(ctx context.Context, cmds []*discordgo.ApplicationCommand) error {
+	botCommands := make([]BotCommand, 0, len(cmds))
+	commands := make(map[string]bool, len(cmds))
+	for _, cmd := range cmds {
+		name := strings.ToLower(cmd.Name)
+		botCommands = append(botCommands, BotCommand{Command: name, Description: cmd.Description})
+		commands[name] = true
+	}
+
+	if err := a.client.setMyCommands(ctx, botCommands); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.commands = commands
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *Adapter) hasCommand(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.commands[name]
+}
+
+// Send implements platform.Platform.
+func (a *Adapter) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	return a.send(&sendMessage{ChatID: channelID, Text: renderMessage(msg), ParseMode: "HTML"})
+}
+
+// Edit implements platform.Platform.
+func (a *Adapter) Edit(channelID, messageID string, msg types.MessageSend) (*types.SentMessage, error) {
+	id, err := parseMessageID(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := a.client.editMessageText(context.Background(), &sendMessage{
+		ChatID:    channelID,
+		MessageID: id,
+		Text:      renderMessage(msg),
+		ParseMode: "HTML",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return convertSent(sent), nil
+}
+
+// Delete implements platform.Platform.
+func (a *Adapter) Delete(channelID, messageID string) error {
+	id, err := parseMessageID(messageID)
+	if err != nil {
+		return err
+	}
+	return a.client.deleteMessage(context.Background(), channelID, id)
+}
+
+// React implements platform.Platform. Bots may only use the emoji Telegram
+// allows as reactions.
+func (a *Adapter) React(channelID, messageID, emoji string) error {
+	id, err := parseMessageID(messageID)
+	if err != nil {
+		return err
+	}
+	return a.client.setMessageReaction(context.Background(), channelID, id, emoji)
+}
+
+// SetPresence implements platform.Platform. Telegram bots have no presence
+// or activity, so it does nothing.
+func (a *Adapter) SetPresence(platform.Status) error { return nil }
+
+// Respond implements platform.Platform. Replies to messages and commands
+// quote the triggering message; replies to button presses are sent to the
+// chat the button was in.
+func (a *Adapter) Respond(e types.Event, msg types.MessageSend, edit *string) (*types.SentMessage, error) {
+	if e.ChannelID == "" || e.ChannelID == "0" {
+		return nil, fmt.Errorf("unsupported Telegram context %T", e.Context)
+	}
+	if edit != nil {
+		return a.Edit(e.ChannelID, *edit, msg)
+	}
+
+	params := &sendMessage{ChatID: e.ChannelID, Text: renderMessage(msg), ParseMode: "HTML"}
+	if u, ok := e.Context.(*Update); ok && u.Message != nil {
+		params.ReplyParameters = &replyParameters{MessageID: u.Message.ID, AllowSendingWithoutReply: true}
+	}
+	return a.send(params)
+}
+
+func (a *Adapter) send(params *sendMessage) (*types.SentMessage, error) {
+	sent, err := a.client.sendMessage(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	return convertSent(sent), nil
+}
+
+func convertSent(m *Message) *types.SentMessage {
+	return &types.SentMessage{
+		ID:        strconv.Itoa(m.ID),
+		ChannelID: strconv.FormatInt(m.Chat.ID, 10),
+		Platform:  Name,
+		Raw:       m,
+	}
+}
+
+func parseMessageID(messageID string) (int, error) {
+	id, err := strconv.Atoi(messageID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Telegram message ID %q", messageID)
+	}
+	return id, nil
+}
@@ -0,0 +1,282 @@
+package telegram_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/platform/telegram"
+	"github.com/luvixsocial/whiskercat/platform/telegram/telegramtest"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+var (
+	bot   = telegramtest.User{ID: 1, IsBot: true, FirstName: "WhiskerCat", Username: "whiskercat_bot"}
+	alice = telegramtest.User{ID: 7, FirstName: "Alice"}
+)
+
+// connect starts a Bot API server and an adapter polling it with a /ping
+// command set, and returns the channel the adapter's events arrive on.
+func connect(t *testing.T) (*telegramtest.Server, *telegram.Adapter, <-chan types.Event) {
+	t.Helper()
+	api := telegramtest.NewServer("token", bot)
+	t.Cleanup(api.Close)
+
+	a, err := telegram.New(types.TelegramConfig{Token: "token", APIURL: api.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = a.SetCommands(context.Background(), []*discordgo.ApplicationCommand{{Name: "Ping", Description: "Check the bot is alive"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan types.Event, 16)
+	a.HandleEvents(func(evt types.Event) { events <- evt })
+	if err := a.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Disconnect(context.Background()) })
+
+	if evt := next(t, events); evt.Type != types.EventConnected {
+		t.Fatalf("first event is %s, want %s", evt.Type, types.EventConnected)
+	}
+	return api, a, events
+}
+
+func next(t *testing.T, events <-chan types.Event) types.Event {
+	t.Helper()
+	select {
+	case evt := <-events:
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return types.Event{}
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	author := types.User{ID: "7", Username: "Alice"}
+
+	// message checks a MessageCreate with the given content.
+	message := func(content string) func(t *testing.T, evt types.Event) {
+		return func(t *testing.T, evt types.Event) {
+			if msg := evt.Data.(types.MessageCallback); msg.Content != content {
+				t.Errorf("content = %q, want %q", msg.Content, content)
+			}
+		}
+	}
+	// command checks an InteractionCreate for ping with the given arguments.
+	command := func(args string, dm bool) func(t *testing.T, evt types.Event) {
+		return func(t *testing.T, evt types.Event) {
+			i := evt.Data.(types.InteractionCallback)
+			if i.Name != "ping" || i.Args != args || i.Fields != nil || i.Author != author || i.DM != dm {
+				t.Errorf("interaction = %+v, want ping with args %q and DM %v", i, args, dm)
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		inject  func(api *telegramtest.Server)
+		want    types.EventType
+		channel string
+		check   func(t *testing.T, evt types.Event)
+	}{
+		{
+			name:    "private message",
+			inject:  func(api *telegramtest.Server) { api.Message(42, alice, "hello") },
+			want:    types.MessageCreate,
+			channel: "42",
+			check: func(t *testing.T, evt types.Event) {
+				msg := evt.Data.(types.MessageCallback)
+				if msg.ID == "" || msg.Content != "hello" || msg.Author != author || !msg.DM || msg.CreatedAt.IsZero() {
+					t.Errorf("message = %+v", msg)
+				}
+				if u, ok := evt.Context.(*telegram.Update); !ok || u.Message == nil {
+					t.Errorf("Context = %#v, want the update", evt.Context)
+				}
+			},
+		},
+		{
+			name:    "group message",
+			inject:  func(api *telegramtest.Server) { api.Message(-100, alice, "hello") },
+			want:    types.MessageCreate,
+			channel: "-100",
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.DM {
+					t.Error("group message is marked as a DM")
+				}
+			},
+		},
+		{
+			name:    "message from a bot",
+			inject:  func(api *telegramtest.Server) { api.Message(-100, telegramtest.User{ID: 2, IsBot: true}, "beep") },
+			want:    types.MessageCreate,
+			channel: "-100",
+			check: func(t *testing.T, evt types.Event) {
+				if !evt.Bot {
+					t.Error("message from a bot is not marked as such")
+				}
+			},
+		},
+		{
+			name:    "edit",
+			inject:  func(api *telegramtest.Server) { api.Edit(42, alice, 5, "edited") },
+			want:    types.MessageUpdate,
+			channel: "42",
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.ID != "5" || msg.Content != "edited" || msg.EditedAt.IsZero() {
+					t.Errorf("edit = %+v", msg)
+				}
+			},
+		},
+		{
+			name:    "command",
+			inject:  func(api *telegramtest.Server) { api.Message(42, alice, "/ping") },
+			want:    types.InteractionCreate,
+			channel: "42",
+			check:   command("", true),
+		},
+		{
+			name:    "command with arguments",
+			inject:  func(api *telegramtest.Server) { api.Message(42, alice, "/ping  fast   please ") },
+			want:    types.InteractionCreate,
+			channel: "42",
+			check:   command("fast   please", true),
+		},
+		{
+			name:    "command addressed to the bot",
+			inject:  func(api *telegramtest.Server) { api.Message(-100, alice, "/PING@WhiskerCat_Bot now") },
+			want:    types.InteractionCreate,
+			channel: "-100",
+			check:   command("now", false),
+		},
+		{
+			name:    "command addressed to another bot",
+			inject:  func(api *telegramtest.Server) { api.Message(-100, alice, "/ping@other_bot") },
+			want:    types.MessageCreate,
+			channel: "-100",
+			check:   message("/ping@other_bot"),
+		},
+		{
+			name:    "unknown command",
+			inject:  func(api *telegramtest.Server) { api.Message(42, alice, "/start") },
+			want:    types.MessageCreate,
+			channel: "42",
+			check:   message("/start"),
+		},
+		{
+			name:    "button press",
+			inject:  func(api *telegramtest.Server) { api.Press(-100, alice, 5, "vote:yes") },
+			want:    types.InteractionCreate,
+			channel: "-100",
+			check: func(t *testing.T, evt types.Event) {
+				if i := evt.Data.(types.InteractionCallback); i.Name != "vote:yes" || i.Author != author || i.DM || !i.Button {
+					t.Errorf("interaction = %+v, want vote:yes", i)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, a, events := connect(t)
+
+			tt.inject(api)
+
+			evt := next(t, events)
+			if evt.Type != tt.want || evt.Name != string(tt.want) {
+				t.Fatalf("event %s (%s), want %s", evt.Type, evt.Name, tt.want)
+			}
+			if evt.Platform != telegram.Name || evt.Session != a || evt.ChannelID != tt.channel {
+				t.Errorf("event from %q in %q, want Telegram in %s", evt.Platform, evt.ChannelID, tt.channel)
+			}
+			tt.check(t, evt)
+		})
+	}
+}
+
+func TestButtonPressIsAnswered(t *testing.T) {
+	api, _, events := connect(t)
+	id := api.Press(-100, alice, 5, "vote:yes")
+	next(t, events)
+
+	// The query is answered in the background.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, call := range api.Calls() {
+			if call.Method == "answerCallbackQuery" && call.Params["callback_query_id"] == id {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("callback query %s was not answered; calls: %+v", id, api.Calls())
+}
+
+func TestSendEmbed(t *testing.T) {
+	url, photo := "https://example.com/a?b&c", "https://example.com/cat.png"
+
+	tests := []struct {
+		name string
+		msg  types.MessageSend
+		want string
+	}{
+		{
+			name: "plain",
+			msg:  types.MessageSend{Content: "1 < 2 & 3"},
+			want: "1 &lt; 2 &amp; 3",
+		},
+		{
+			name: "embed",
+			msg: types.MessageSend{Content: "Status:", Embed: &types.Embed{
+				Title:       "Cats & dogs",
+				URL:         &url,
+				Description: "all <good>",
+				Fields:      &[]types.EmbedField{{Name: "Up", Value: "yes"}},
+				PhotoURL:    &photo,
+				Footer:      &types.EmbedFooter{Text: "footer"},
+			}},
+			want: "Status:\n\n<blockquote>" +
+				`<a href="https://example.com/a?b&amp;c"><b>Cats &amp; dogs</b></a>` +
+				"\n\nall &lt;good&gt;" +
+				"\n\n<b>Up</b>\nyes" +
+				"\n\n" + `<a href="` + photo + `">` + photo + `</a>` +
+				"\n\n<i>footer</i></blockquote>",
+		},
+		{
+			name: "embed without content",
+			msg:  types.MessageSend{Embed: &types.Embed{Title: "Status"}},
+			want: "<blockquote><b>Status</b></blockquote>",
+		},
+		{
+			name: "empty embed",
+			msg:  types.MessageSend{Content: "hi", Embed: &types.Embed{}},
+			want: "hi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, a, _ := connect(t)
+
+			if _, err := a.Send("42", tt.msg); err != nil {
+				t.Fatal(err)
+			}
+
+			var send *telegramtest.Call
+			for _, call := range api.Calls() {
+				if call.Method == "sendMessage" {
+					send = &call
+				}
+			}
+			if send == nil {
+				t.Fatalf("no sendMessage call; calls: %+v", api.Calls())
+			}
+			if send.Params["text"] != tt.want || send.Params["parse_mode"] != "HTML" {
+				t.Errorf("sent %q in %v, want %q in HTML", send.Params["text"], send.Params["parse_mode"], tt.want)
+			}
+		})
+	}
+}
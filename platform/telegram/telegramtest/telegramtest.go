@@ -0,0 +1,265 @@
+// Package telegramtest provides an in-process stand-in for the Telegram Bot
+// API that implements the methods used by the telegram adapter.
+//
+// Tests inject updates as if users had sent them and inspect the calls the
+// bot made:
+//
+//	api := telegramtest.NewServer("token", telegramtest.User{ID: 1, IsBot: true, Username: "whiskercat_bot"})
+//	defer api.Close()
+//	adapter, _ := telegram.New(types.TelegramConfig{Token: "token", APIURL: api.URL})
+//	...
+//	api.Message(42, telegramtest.User{ID: 7, FirstName: "Alice"}, "/ping")
+package telegramtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// User is a Telegram user.
+type User struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Call is a Bot API method invoked by the bot.
+type Call struct {
+	Method string
+	Params map[string]any
+}
+
+// Server is a fake Bot API server.
+type Server struct {
+	*httptest.Server
+
+	token string
+	bot   User
+
+	mu       sync.Mutex
+	updates  []map[string]any
+	calls    []Call
+	commands []any
+	nextMsg  int
+	nextCB   int
+	wake     chan struct{}
+}
+
+// NewServer starts a Bot API server that accepts token for bot.
+func NewServer(token string, bot User) *Server {
+	s := &Server{token: token, bot: bot, wake: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Message injects a text message from a user into a private or group chat
+// and returns its message ID. Messages starting with "/" carry a
+// bot_command entity like the real API.
+func (s *Server) Message(chatID int64, from User, text string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := s.messageLocked(chatID, from, text)
+	s.pushLocked(map[string]any{"message": msg})
+	return msg["message_id"].(int)
+}
+
+// Edit injects an edit of a user's message.
+func (s *Server) Edit(chatID int64, from User, messageID int, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := map[string]any{
+		"message_id": messageID,
+		"from":       from,
+		"chat":       chat(chatID),
+		"date":       time.Now().Unix(),
+		"edit_date":  time.Now().Unix(),
+		"text":       text,
+	}
+	s.pushLocked(map[string]any{"edited_message": msg})
+}
+
+// Press injects a callback query for an inline button with data pressed on
+// messageID and returns the query ID.
+func (s *Server) Press(chatID int64, from User, messageID int, data string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextCB++
+	id := "cb" + strconv.Itoa(s.nextCB)
+	s.pushLocked(map[string]any{"callback_query": map[string]any{
+		"id":      id,
+		"from":    from,
+		"message": map[string]any{"message_id": messageID, "chat": chat(chatID), "date": time.Now().Unix()},
+		"data":    data,
+	}})
+	return id
+}
+
+// Calls returns every method the bot called except getMe and getUpdates,
+// in order.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Commands returns the command menu last set with setMyCommands.
+func (s *Server) Commands() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands
+}
+
+func (s *Server) messageLocked(chatID int64, from User, text string) map[string]any {
+	s.nextMsg++
+	msg := map[string]any{
+		"message_id": s.nextMsg,
+		"from":       from,
+		"chat":       chat(chatID),
+		"date":       time.Now().Unix(),
+		"text":       text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		msg["entities"] = []map[string]any{{"type": "bot_command", "offset": 0, "length": len(command)}}
+	}
+	return msg
+}
+
+func (s *Server) pushLocked(update map[string]any) {
+	update["update_id"] = len(s.updates) + 1
+	s.updates = append(s.updates, update)
+
+	close(s.wake)
+	s.wake = make(chan struct{})
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	token, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+	if !ok || token != s.token {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := map[string]any{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			writeError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+			return
+		}
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, s.bot)
+	case "getUpdates":
+		s.getUpdates(w, r, params)
+	case "sendMessage":
+		s.record(method, params)
+		s.mu.Lock()
+		msg := s.messageLocked(chatID(params), s.bot, str(params["text"]))
+		s.mu.Unlock()
+		writeResult(w, msg)
+	case "editMessageText":
+		s.record(method, params)
+		writeResult(w, map[string]any{
+			"message_id": int(num(params["message_id"])),
+			"from":       s.bot,
+			"chat":       chat(chatID(params)),
+			"date":       time.Now().Unix(),
+			"edit_date":  time.Now().Unix(),
+			"text":       str(params["text"]),
+		})
+	case "setMyCommands":
+		s.record(method, params)
+		s.mu.Lock()
+		s.commands, _ = params["commands"].([]any)
+		s.mu.Unlock()
+		writeResult(w, true)
+	case "deleteMessage", "setMessageReaction", "answerCallbackQuery":
+		s.record(method, params)
+		writeResult(w, true)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found: method not found")
+	}
+}
+
+// getUpdates long-polls for updates with update_id >= offset.
+func (s *Server) getUpdates(w http.ResponseWriter, r *http.Request, params map[string]any) {
+	offset := int(num(params["offset"]))
+	timeout := time.Duration(num(params["timeout"])) * time.Second
+
+	s.mu.Lock()
+	if len(s.updates) <= max(offset-1, 0) && timeout > 0 {
+		wake := s.wake
+		s.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-time.After(timeout):
+		case <-r.Context().Done():
+			return
+		}
+		s.mu.Lock()
+	}
+	start := max(offset-1, 0)
+	start = min(start, len(s.updates))
+	pending := append([]map[string]any{}, s.updates[start:]...)
+	s.mu.Unlock()
+
+	writeResult(w, pending)
+}
+
+func (s *Server) record(method string, params map[string]any) {
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: method, Params: params})
+	s.mu.Unlock()
+}
+
+func chat(id int64) map[string]any {
+	chatType := "private"
+	if id < 0 {
+		chatType = "group"
+	}
+	return map[string]any{"id": id, "type": chatType}
+}
+
+func chatID(params map[string]any) int64 {
+	switch v := params["chat_id"].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		id, _ := strconv.ParseInt(v, 10, 64)
+		return id
+	}
+	return 0
+}
+
+func num(v any) float64 {
+	f, _ := v.(float64)
+	return f
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func writeError(w http.ResponseWriter, status int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": status, "description": description})
+}
@@ -0,0 +1,245 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// pollTimeout is how long the Bot API may hold a getUpdates long poll.
+const pollTimeout = 30 * time.Second
+
+// Update is a Bot API update. It is used as the types.Event.Context of
+// Telegram events.
+type Update struct {
+	ID            int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	EditedMessage *Message       `json:"edited_message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// Message is a Telegram message.
+type Message struct {
+	ID       int             `json:"message_id"`
+	From     *User           `json:"from,omitempty"`
+	Chat     Chat            `json:"chat"`
+	Date     int64           `json:"date"`
+	EditDate int64           `json:"edit_date,omitempty"`
+	Text     string          `json:"text,omitempty"`
+	Caption  string          `json:"caption,omitempty"`
+	Entities []MessageEntity `json:"entities,omitempty"`
+	ReplyTo  *Message        `json:"reply_to_message,omitempty"`
+}
+
+// User is a Telegram user or bot.
+type User struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat is a private chat, group, supergroup or channel.
+type Chat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// MessageEntity marks a special span of a message's text, such as a command.
+type MessageEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	User   *User  `json:"user,omitempty"` // For "text_mention" entities
+}
+
+// CallbackQuery is sent when a user presses an inline keyboard button.
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+// run is the getUpdates loop. It announces the connection before the first
+// poll, since a poll may be held open for pollTimeout.
+func (a *Adapter) run(ctx context.Context, offset int64) {
+	a.ready.Fire()
+	if offset > 0 {
+		a.emitState(types.EventResumed)
+	} else {
+		a.emitState(types.EventConnected)
+	}
+
+	for {
+		updates, err := a.client.getUpdates(ctx, offset, pollTimeout)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return
+			}
+			var apiErr *Error
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				select {
+				case <-time.After(time.Duration(apiErr.RetryAfter) * time.Second):
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			log.Printf("Telegram getUpdates failed: %v\n", err)
+			a.emitState(types.EventDisconnected)
+			return
+		}
+
+		for _, u := range updates {
+			offset = u.ID + 1
+			a.setOffset(offset)
+			a.handleUpdate(u)
+		}
+	}
+}
+
+// handleUpdate normalizes a single update.
+func (a *Adapter) handleUpdate(u *Update) {
+	emit := func(eventType types.EventType, bot bool, chatID int64, data any) {
+		a.emit(types.Event{
+			Name:      string(eventType),
+			Type:      eventType,
+			Platform:  Name,
+			Bot:       bot,
+			Context:   u,
+			Session:   a,
+			Data:      data,
+			ChannelID: strconv.FormatInt(chatID, 10),
+		})
+	}
+
+	switch {
+	case u.Message != nil:
+		m := u.Message
+		if name, args, ok := a.parseCommand(m); ok {
+			emit(types.InteractionCreate, isBot(m.From), m.Chat.ID, types.InteractionCallback{
+				Name:   name,
+				Args:   args,
+				Author: convertUser(m.From),
+				DM:     m.Chat.Type == "private",
+			})
+			return
+		}
+		emit(types.MessageCreate, isBot(m.From), m.Chat.ID, convertMessage(m))
+
+	case u.EditedMessage != nil:
+		m := u.EditedMessage
+		emit(types.MessageUpdate, isBot(m.From), m.Chat.ID, convertMessage(m))
+
+	case u.CallbackQuery != nil:
+		q := u.CallbackQuery
+		// Stop the client's loading indicator; replies are sent as messages.
+		go func() {
+			if err := a.client.answerCallbackQuery(context.Background(), q.ID); err != nil {
+				log.Printf("Telegram answerCallbackQuery failed: %v\n", err)
+			}
+		}()
+
+		var chatID int64
+		var dm bool
+		if q.Message != nil {
+			chatID, dm = q.Message.Chat.ID, q.Message.Chat.Type == "private"
+		}
+		emit(types.InteractionCreate, q.From.IsBot, chatID, types.InteractionCallback{
+			Name:   q.Data,
+			Author: convertUser(&q.From),
+			DM:     dm,
+			Button: true,
+		})
+	}
+}
+
+// parseCommand recognizes messages starting with one of the commands set
+// with SetCommands, like "/ping" or "/ping@WhiskerCatBot", and returns the
+// command name and the rest of the text. The command package assigns the
+// arguments to the command's options.
+func (a *Adapter) parseCommand(m *Message) (name, args string, ok bool) {
+	if len(m.Entities) == 0 || m.Entities[0].Type != "bot_command" || m.Entities[0].Offset != 0 {
+		return "", "", false
+	}
+
+	// Entity offsets count UTF-16 code units, but commands are ASCII.
+	command, args := m.Text, ""
+	if i := strings.IndexFunc(command, unicode.IsSpace); i >= 0 {
+		command, args = command[:i], strings.TrimSpace(command[i:])
+	}
+	command = strings.TrimPrefix(command, "/")
+	if name, target, found := strings.Cut(command, "@"); found {
+		if !strings.EqualFold(target, a.username()) {
+			return "", "", false
+		}
+		command = name
+	}
+	command = strings.ToLower(command)
+
+	if !a.hasCommand(command) {
+		return "", "", false
+	}
+	return command, args, true
+}
+
+// convertMessage normalizes a message. Telegram has no servers, and only
+// text mentions of users without a username carry an ID.
+func convertMessage(m *Message) types.MessageCallback {
+	msg := types.MessageCallback{
+		ID:        strconv.Itoa(m.ID),
+		ChannelID: strconv.FormatInt(m.Chat.ID, 10),
+		Content:   text(m),
+		Author:    convertUser(m.From),
+		CreatedAt: time.Unix(m.Date, 0),
+		DM:        m.Chat.Type == "private",
+	}
+	if m.EditDate != 0 {
+		msg.EditedAt = time.Unix(m.EditDate, 0)
+	}
+	if m.ReplyTo != nil {
+		msg.ReplyTo = strconv.Itoa(m.ReplyTo.ID)
+	}
+	for _, entity := range m.Entities {
+		if entity.Type == "text_mention" && entity.User != nil {
+			msg.Mentions.Users = append(msg.Mentions.Users, strconv.FormatInt(entity.User.ID, 10))
+		}
+	}
+	return msg
+}
+
+// text returns the text of a message, or the caption of a media message.
+func text(m *Message) string {
+	if m.Text != "" {
+		return m.Text
+	}
+	return m.Caption
+}
+
+func isBot(user *User) bool {
+	return user != nil && user.IsBot
+}
+
+func convertUser(user *User) types.User {
+	if user == nil {
+		return types.User{}
+	}
+	username := user.Username
+	if username == "" {
+		username = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	return types.User{
+		ID:       strconv.FormatInt(user.ID, 10),
+		Username: username,
+	}
+}
@@ -0,0 +1,71 @@
+package whiskercat
+
+import "github.com/luvixsocial/whiskercat/types"
+
+// Send posts a message to the referenced channel.
+func (b *Bot) Send(to types.ChannelRef, msg types.MessageSend) (*types.SentMessage, error) {
+	p, err := b.lookupPlatform(to.Platform)
+	if err != nil {
+		return nil, err
+	}
+	return p.Send(to.ID, msg)
+}
+
+// Edit replaces the content of the referenced message.
+func (b *Bot) Edit(ref types.MessageRef, msg types.MessageSend) (*types.SentMessage, error) {
+	p, err := b.lookupPlatform(ref.Channel.Platform)
+	if err != nil {
+		return nil, err
+	}
+	return p.Edit(ref.Channel.ID, ref.ID, msg)
+}
+
+// Delete deletes the referenced message.
+func (b *Bot) Delete(ref types.MessageRef) error {
+	p, err := b.lookupPlatform(ref.Channel.Platform)
+	if err != nil {
+		return err
+	}
+	return p.Delete(ref.Channel.ID, ref.ID)
+}
+
+// React adds an emoji reaction to the referenced message.
+func (b *Bot) React(ref types.MessageRef, emoji string) error {
+	p, err := b.lookupPlatform(ref.Channel.Platform)
+	if err != nil {
+		return err
+	}
+	return p.React(ref.Channel.ID, ref.ID, emoji)
+}
+
+// Send posts a message to the referenced channel using the default bot.
+func Send(to types.ChannelRef, msg types.MessageSend) (*types.SentMessage, error) {
+	if defaultBot == nil {
+		return nil, errNotConfigured
+	}
+	return defaultBot.Send(to, msg)
+}
+
+// Edit edits the referenced message using the default bot.
+func Edit(ref types.MessageRef, msg types.MessageSend) (*types.SentMessage, error) {
+	if defaultBot == nil {
+		return nil, errNotConfigured
+	}
+	return defaultBot.Edit(ref, msg)
+}
+
+// Delete deletes the referenced message using the default bot.
+func Delete(ref types.MessageRef) error {
+	if defaultBot == nil {
+		return errNotConfigured
+	}
+	return defaultBot.Delete(ref)
+}
+
+// React reacts to the referenced message using the default bot.
+func React(ref types.MessageRef, emoji string) error {
+	if defaultBot == nil {
+		return errNotConfigured
+	}
+	return defaultBot.React(ref, emoji)
+}
@@ -1,56 +1,185 @@
-package main
+package whiskercat
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/platform/discord"
+	"github.com/luvixsocial/whiskercat/platform/revolt"
+	"github.com/luvixsocial/whiskercat/platform/telegram"
 	"github.com/sentinelb51/revoltgo"
 )
 
-// Start initializes and runs both the Discord and Revolt clients concurrently.
-// It will block until both clients are successfully started or fatally fail.
+// ErrNoPlatforms is returned by Start when the bot has no configured platform.
+var ErrNoPlatforms = errors.New("whiskercat: no platforms are configured")
+
+// ErrStarted is returned by Start while an earlier call is connecting or
+// has connected a platform.
+var ErrStarted = errors.New("whiskercat: bot is already started")
+
+// ErrStopped is returned by Start once the bot has been stopped. A stopped
+// bot cannot be restarted; create a new one instead.
+var ErrStopped = errors.New("whiskercat: bot is stopped")
+
+// Start connects every configured platform of the bot concurrently and
+// blocks until each connection attempt finishes or ctx is done.
 //
-// This function uses a WaitGroup to ensure both clients are launched before continuing.
-// In the event of an error while opening a client, the program will terminate immediately.
-func Start() (*discordgo.Session, *revoltgo.Session) {
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	// Start Discord client in a separate goroutine
-	go func() {
-		defer wg.Done()
-
-		if Discord == nil {
-			log.Fatalln("Discord client is not initialized")
-		}
+// The returned error joins the failure of every platform that did not
+// connect; platforms that did connect stay connected until Stop and are
+// reconnected by the supervisor if their gateway drops. A platform that
+// finishes connecting after ctx is done is disconnected again. Use Ready to
+// learn when the gateways have delivered their Ready events.
+//
+// Start returns ErrStarted while the bot is running; it can only be called
+// again if no platform connected. A bot cannot be started again after Stop.
+func (b *Bot) Start(ctx context.Context) error {
+	if len(b.platforms) == 0 {
+		return ErrNoPlatforms
+	}
+	b.lifecycleMu.Lock()
+	switch {
+	case b.stopped:
+		b.lifecycleMu.Unlock()
+		return ErrStopped
+	case b.started:
+		b.lifecycleMu.Unlock()
+		return ErrStarted
+	}
+	b.started = true
+	b.lifecycleMu.Unlock()
 
-		if err := Discord.Open(); err != nil {
-			log.Fatalf("Error starting Discord client: %v", err)
-		}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		errs      []error
+		connected []platform.Platform
+	)
 
-		fmt.Println("✅ Discord client started!")
-	}()
+	for name, p := range b.platforms {
+		wg.Add(1)
 
-	// Start Revolt client in a separate goroutine
-	go func() {
-		defer wg.Done()
+		// Start each client in a separate goroutine
+		go func() {
+			defer wg.Done()
 
-		if Revolt == nil {
-			log.Fatalln("Revolt client is not initialized")
-		}
+			done := make(chan error, 1)
+			go func() { done <- p.Connect(ctx) }()
 
-		if err := Revolt.Open(); err != nil {
-			log.Fatalf("Error starting Revolt client: %v", err)
-		}
+			var err error
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ctx.Err()
+				b.supervisor.abandon(name)
+				go b.disconnectLate(p, done)
+			}
 
-		fmt.Println("✅ Revolt client started!")
-		time.Sleep(1 * time.Second) // Optional: delay to prevent startup race conditions
-	}()
+			b.supervisor.started(name, err)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			connected = append(connected, p)
+
+			log.Printf("✅ %s client started!\n", name)
+		}()
+	}
 
 	wg.Wait()
 
-	return Discord, Revolt
+	if len(connected) > 0 {
+		go b.watchReady(connected)
+	} else {
+		b.lifecycleMu.Lock()
+		b.started = false
+		b.lifecycleMu.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
+
+// disconnectLate waits for a connect that Start gave up on and disconnects
+// the platform if it connected after all, since nothing supervises it.
+func (b *Bot) disconnectLate(p platform.Platform, done <-chan error) {
+	if err := <-done; err != nil {
+		return
+	}
+	if err := p.Disconnect(context.Background()); err != nil {
+		log.Printf("Error disconnecting %s after a late connect: %v\n", p.Name(), err)
+	}
+}
+
+// watchReady closes b.ready once every connected platform has signalled
+// Ready, and gives up when the bot is stopped.
+func (b *Bot) watchReady(connected []platform.Platform) {
+	for _, p := range connected {
+		select {
+		case <-p.Ready():
+		case <-b.supervisor.ctx.Done():
+			return
+		}
+	}
+	b.readyOnce.Do(func() { close(b.ready) })
+}
+
+// Ready returns a channel that is closed once the gateway of every platform
+// that connected in Start has delivered its Ready event. It is never closed
+// if no platform connected.
+func (b *Bot) Ready() <-chan struct{} {
+	return b.ready
+}
+
+// WaitReady blocks until Ready fires or ctx is done.
+func (b *Bot) WaitReady(ctx context.Context) error {
+	select {
+	case <-b.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Discord returns the bot's discordgo session, or nil if Discord is not configured.
+func (b *Bot) Discord() *discordgo.Session {
+	if p, ok := b.platforms[discord.Name].(*discord.Adapter); ok {
+		return p.Session()
+	}
+	return nil
+}
+
+// Revolt returns the bot's revoltgo session, or nil if Revolt is not configured.
+func (b *Bot) Revolt() *revoltgo.Session {
+	if p, ok := b.platforms[revolt.Name].(*revolt.Adapter); ok {
+		return p.Session()
+	}
+	return nil
+}
+
+// Telegram returns the bot's Telegram adapter, or nil if Telegram is not configured.
+func (b *Bot) Telegram() *telegram.Adapter {
+	p, _ := b.platforms[telegram.Name].(*telegram.Adapter)
+	return p
+}
+
+// Start connects the default bot.
+func Start(ctx context.Context) error {
+	if defaultBot == nil {
+		return errNotConfigured
+	}
+	return defaultBot.Start(ctx)
+}
+
+// WaitReady waits for the default bot's platforms to become ready.
+func WaitReady(ctx context.Context) error {
+	if defaultBot == nil {
+		return errNotConfigured
+	}
+	return defaultBot.WaitReady(ctx)
 }
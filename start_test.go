@@ -0,0 +1,183 @@
+package whiskercat_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+	"github.com/luvixsocial/whiskercat/whiskercattest"
+)
+
+// newBot creates a bot on fake with opts, stopped when the test ends.
+func newBot(t *testing.T, fake *whiskercattest.Platform, opts ...whiskercat.Option) *whiskercat.Bot {
+	t.Helper()
+	b := whiskercat.NewWithPlatforms([]platform.Platform{fake}, opts...)
+	t.Cleanup(func() { b.Stop(context.Background()) })
+	return b
+}
+
+// within fails the test unless ch is closed within a second.
+func within(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestStartTwice(t *testing.T) {
+	b := newBot(t, whiskercattest.NewPlatform(whiskercattest.Name))
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(context.Background()); !errors.Is(err, whiskercat.ErrStarted) {
+		t.Errorf("second Start = %v, want ErrStarted", err)
+	}
+	if err := b.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Start(context.Background()); !errors.Is(err, whiskercat.ErrStopped) {
+		t.Errorf("Start after Stop = %v, want ErrStopped", err)
+	}
+}
+
+func TestStartAgainAfterFailure(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	b := newBot(t, fake)
+
+	refused := errors.New("connection refused")
+	fake.OnConnect(func(context.Context) error { return refused })
+	if err := b.Start(context.Background()); !errors.Is(err, refused) {
+		t.Fatalf("Start = %v, want %v", err, refused)
+	}
+
+	// Nothing connected, so the bot can be started again.
+	fake.OnConnect(nil)
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("Start after a failed start = %v", err)
+	}
+	if !fake.Connected() {
+		t.Error("platform is not connected")
+	}
+}
+
+func TestStartLateConnect(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	b := newBot(t, fake,
+		whiskercat.WithDispatch(whiskercat.DispatchConfig{}),
+		whiskercat.WithReconnectPolicy(whiskercat.ReconnectPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1, DownAfter: 1}),
+	)
+
+	// The gateway ignores the context, like discordgo's.
+	gate := make(chan struct{})
+	fake.OnConnect(func(context.Context) error {
+		<-gate
+		return nil
+	})
+	disconnected := make(chan struct{})
+	b.OnEvent(func(evt types.Event) {
+		if evt.Type == types.EventDisconnected {
+			close(disconnected)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Start = %v, want the deadline", err)
+	}
+
+	close(gate)
+	within(t, disconnected, "the late connect to be undone")
+
+	// The supervisor leaves the abandoned platform alone.
+	time.Sleep(20 * time.Millisecond)
+	if fake.Connected() {
+		t.Error("platform was reconnected after Start gave up on it")
+	}
+	if h := b.Health(whiskercattest.Name); h != types.Down {
+		t.Errorf("Health = %v, want %v", h, types.Down)
+	}
+}
+
+func TestReady(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	fire := fake.HoldReady()
+	b := newBot(t, fake)
+
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-b.Ready():
+		t.Fatal("Ready fired before the platform was ready")
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.WaitReady(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitReady = %v, want the deadline", err)
+	}
+
+	fire()
+	within(t, b.Ready(), "Ready")
+	if err := b.WaitReady(context.Background()); err != nil {
+		t.Errorf("WaitReady once ready = %v", err)
+	}
+}
+
+func TestStopDeadline(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	b := newBot(t, fake, whiskercat.WithDispatch(whiskercat.DispatchConfig{Workers: 1, QueueSize: 4}))
+
+	started, gate := make(chan struct{}), make(chan struct{})
+	defer close(gate)
+	b.OnMessageCreate(func(types.Event, types.MessageCallback) {
+		close(started)
+		<-gate
+	})
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	fake.InjectMessage("general", whiskercattest.User("alice"), "hello")
+	within(t, started, "the handler to start")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop = %v, want the deadline while a handler runs", err)
+	}
+	// The platform is disconnected anyway.
+	if fake.Connected() {
+		t.Error("platform is still connected after Stop")
+	}
+}
+
+func TestStopDrains(t *testing.T) {
+	fake := whiskercattest.NewPlatform(whiskercattest.Name)
+	b := newBot(t, fake, whiskercat.WithDispatch(whiskercat.DispatchConfig{Workers: 1, QueueSize: 4}))
+
+	handled := make(chan struct{}, 3)
+	b.OnMessageCreate(func(types.Event, types.MessageCallback) {
+		time.Sleep(5 * time.Millisecond)
+		handled <- struct{}{}
+	})
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		fake.InjectMessage("general", whiskercattest.User("alice"), "hello")
+	}
+
+	if err := b.Stop(context.Background()); err != nil {
+		t.Errorf("Stop = %v", err)
+	}
+	if n := len(handled); n != 3 {
+		t.Errorf("%d events handled before Stop returned, want 3", n)
+	}
+}
@@ -1,12 +1,83 @@
-package main
+package whiskercat
 
 import (
-	"github.com/bwmarrin/discordgo"
-	"github.com/sentinelb51/revoltgo"
-)
+	"sort"
+	"sync"
+	"time"
 
-// Declaring the global variables
-var (
-	Revolt  *revoltgo.Session
-	Discord *discordgo.Session
+	"github.com/luvixsocial/whiskercat/link"
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/state"
 )
+
+// Bot owns the platform sessions, event handlers and caches of one bot.
+// Several bots can run in the same process.
+type Bot struct {
+	// platforms holds the adapters, keyed by platform name.
+	platforms map[string]platform.Platform
+	// platformNames maps the lower-cased names used in references to the
+	// keys of platforms.
+	platformNames map[string]string
+
+	handlers   []*handlerEntry
+	middleware []Middleware
+	handlersMu sync.RWMutex
+
+	cooldowns     map[string]time.Time
+	cooldownMutex sync.Mutex
+
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	supervisor *supervisor
+
+	stateConfig state.Config
+	state       *state.Store
+
+	directoryCache platform.CacheConfig
+
+	linkStore link.Store
+	links     *link.Linker
+
+	dispatchConfig DispatchConfig
+	dispatcher     *dispatcher // nil when handlers run inline
+
+	// inflight counts running handlers so Stop can drain them.
+	inflight    sync.WaitGroup
+	lifecycleMu sync.RWMutex
+	started     bool
+	stopped     bool
+}
+
+// defaultBot backs the package-level helpers; it is set by Config.
+var defaultBot *Bot
+
+// Default returns the bot created by Config, or nil if Config has not been called.
+func Default() *Bot {
+	return defaultBot
+}
+
+// State returns the bot's view of the servers, channels, roles and members
+// it has seen. It is updated before an event is queued for the handlers,
+// so it may already reflect events that have not been handled yet.
+func (b *Bot) State() *state.Store {
+	return b.state
+}
+
+// State returns the default bot's state, or nil if Config has not been called.
+func State() *state.Store {
+	if defaultBot == nil {
+		return nil
+	}
+	return defaultBot.state
+}
+
+// Platforms returns the sorted names of the platforms the bot is configured for.
+func (b *Bot) Platforms() []string {
+	names := make([]string, 0, len(b.platforms))
+	for name := range b.platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,261 @@
+// Package state keeps a cross-platform view of the servers, channels, roles
+// and members a bot can see. It is built only from normalized events, so it
+// works the same for every platform adapter that emits them.
+package state
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/luvixsocial/whiskercat/cache"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Config selects which entities are cached and how many of each are kept.
+// Limits of 0 select cache.DefaultSize; when a limit is reached the least
+// recently used entries are dropped.
+type Config struct {
+	Servers  bool // Cache servers
+	Channels bool // Cache channels and threads
+	Roles    bool // Cache the roles of each server
+	Members  bool // Cache members that joined or changed while connected
+
+	MaxServers  int // Servers kept, which also bounds the servers with cached roles
+	MaxChannels int // Channels kept
+	MaxMembers  int // Members kept
+}
+
+// DefaultConfig caches every entity with the default limits.
+var DefaultConfig = Config{
+	Servers:  true,
+	Channels: true,
+	Roles:    true,
+	Members:  true,
+}
+
+// Stats reports the activity of each of the store's caches.
+type Stats struct {
+	Servers  cache.Stats
+	Channels cache.Stats
+	Roles    cache.Stats
+	Members  cache.Stats
+}
+
+type key struct {
+	platform, id string
+}
+
+type memberKey struct {
+	platform, serverID, userID string
+}
+
+// Store holds the state. Entities are kept until they are deleted or
+// evicted. It is safe for concurrent use.
+type Store struct {
+	servers  *cache.Cache[key, types.Server]
+	channels *cache.Cache[key, types.Channel]
+	roles    *cache.Cache[key, []types.Role] // By server
+	members  *cache.Cache[memberKey, types.Member]
+
+	// rolesMu serializes updates of a server's role list.
+	rolesMu sync.Mutex
+}
+
+// New creates an empty store. Entities disabled in config are never cached.
+func New(config Config) *Store {
+	s := &Store{}
+	if config.Servers {
+		s.servers = cache.New[key, types.Server](config.MaxServers, -1)
+	}
+	if config.Channels {
+		s.channels = cache.New[key, types.Channel](config.MaxChannels, -1)
+	}
+	if config.Roles {
+		s.roles = cache.New[key, []types.Role](config.MaxServers, -1)
+	}
+	if config.Members {
+		s.members = cache.New[memberKey, types.Member](config.MaxMembers, -1)
+	}
+	return s
+}
+
+// Server returns a server with its roles. Its Channels are left nil.
+func (s *Store) Server(platform, serverID string) (types.Server, bool) {
+	if s.servers == nil {
+		return types.Server{}, false
+	}
+	server, ok := s.servers.Get(key{platform, serverID})
+	if ok {
+		server.Roles = s.Roles(platform, serverID)
+	}
+	return server, ok
+}
+
+// Channel returns a channel or thread.
+func (s *Store) Channel(platform, channelID string) (types.Channel, bool) {
+	if s.channels == nil {
+		return types.Channel{}, false
+	}
+	return s.channels.Get(key{platform, channelID})
+}
+
+// Roles returns a server's roles, or nil if they are not known.
+func (s *Store) Roles(platform, serverID string) []types.Role {
+	if s.roles == nil {
+		return nil
+	}
+	roles, _ := s.roles.Get(key{platform, serverID})
+	return slices.Clone(roles)
+}
+
+// Role returns one of a server's roles.
+func (s *Store) Role(platform, serverID, roleID string) (types.Role, bool) {
+	for _, role := range s.Roles(platform, serverID) {
+		if role.ID == roleID {
+			return role, true
+		}
+	}
+	return types.Role{}, false
+}
+
+// Member returns a member seen joining or changing while connected.
+func (s *Store) Member(platform, serverID, userID string) (types.Member, bool) {
+	if s.members == nil {
+		return types.Member{}, false
+	}
+	return s.members.Get(memberKey{platform, serverID, userID})
+}
+
+// Stats reports the activity of the store's caches. Disabled caches report
+// zero.
+func (s *Store) Stats() Stats {
+	var stats Stats
+	if s.servers != nil {
+		stats.Servers = s.servers.Stats()
+	}
+	if s.channels != nil {
+		stats.Channels = s.channels.Stats()
+	}
+	if s.roles != nil {
+		stats.Roles = s.roles.Stats()
+	}
+	if s.members != nil {
+		stats.Members = s.members.Stats()
+	}
+	return stats
+}
+
+// Apply updates the store from an event. Events that do not describe
+// servers, channels, roles or members are ignored.
+func (s *Store) Apply(evt types.Event) {
+	switch data := evt.Data.(type) {
+	case types.Server:
+		switch evt.Type {
+		case types.EventServerCreate, types.EventServerUpdate:
+			s.setServer(evt.Platform, data)
+		case types.EventServerDelete:
+			s.deleteServer(evt.Platform, data.ID)
+		}
+
+	case types.Channel:
+		switch evt.Type {
+		case types.EventChannelCreate, types.EventChannelUpdate, types.EventThreadCreate, types.EventThreadUpdate:
+			s.setChannel(evt.Platform, data)
+		case types.EventChannelDelete, types.EventThreadDelete:
+			if s.channels != nil {
+				s.channels.Delete(key{evt.Platform, data.ID})
+			}
+		}
+
+	case types.Role:
+		switch evt.Type {
+		case types.EventRoleCreate, types.EventRoleUpdate:
+			s.updateRoles(evt.Platform, data.ServerID, func(roles []types.Role) []types.Role {
+				if i := slices.IndexFunc(roles, func(r types.Role) bool { return r.ID == data.ID }); i >= 0 {
+					roles[i] = data
+					return roles
+				}
+				return append(roles, data)
+			})
+		case types.EventRoleDelete:
+			s.updateRoles(evt.Platform, data.ServerID, func(roles []types.Role) []types.Role {
+				return slices.DeleteFunc(roles, func(r types.Role) bool { return r.ID == data.ID })
+			})
+		}
+
+	case types.Member:
+		if s.members == nil || data.ServerID == "" {
+			return
+		}
+		k := memberKey{evt.Platform, data.ServerID, data.User.ID}
+		switch evt.Type {
+		case types.EventMemberJoin, types.EventMemberUpdate:
+			s.members.Set(k, data)
+		case types.EventMemberLeave:
+			s.members.Delete(k)
+		}
+	}
+}
+
+func (s *Store) setServer(platform string, server types.Server) {
+	for _, channel := range server.Channels {
+		s.setChannel(platform, channel)
+	}
+	if server.Roles != nil && s.roles != nil {
+		s.rolesMu.Lock()
+		s.roles.Set(key{platform, server.ID}, slices.Clone(server.Roles))
+		s.rolesMu.Unlock()
+	}
+
+	// Updates the platform could not resolve only carry the ID.
+	if s.servers == nil || server.Name == "" {
+		return
+	}
+	server.Channels, server.Roles = nil, nil
+	s.servers.Set(key{platform, server.ID}, server)
+}
+
+func (s *Store) deleteServer(platform, serverID string) {
+	k := key{platform, serverID}
+	if s.servers != nil {
+		s.servers.Delete(k)
+	}
+	if s.roles != nil {
+		s.roles.Delete(k)
+	}
+	if s.channels != nil {
+		s.channels.DeleteFunc(func(k key, channel types.Channel) bool {
+			return k.platform == platform && channel.ServerID == serverID
+		})
+	}
+	if s.members != nil {
+		s.members.DeleteFunc(func(k memberKey, _ types.Member) bool {
+			return k.platform == platform && k.serverID == serverID
+		})
+	}
+}
+
+func (s *Store) setChannel(platform string, channel types.Channel) {
+	// Updates the platform could not resolve only carry the ID.
+	if s.channels == nil || channel.Type == "" {
+		return
+	}
+	s.channels.Set(key{platform, channel.ID}, channel)
+}
+
+// updateRoles replaces a server's cached roles with update's result. Only
+// servers whose roles are already known are updated.
+func (s *Store) updateRoles(platform, serverID string, update func([]types.Role) []types.Role) {
+	if s.roles == nil {
+		return
+	}
+	s.rolesMu.Lock()
+	defer s.rolesMu.Unlock()
+
+	k := key{platform, serverID}
+	roles, ok := s.roles.Get(k)
+	if !ok {
+		return
+	}
+	s.roles.Set(k, update(slices.Clone(roles)))
+}
@@ -0,0 +1,193 @@
+package state
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+var (
+	general = types.Channel{ID: "c1", ServerID: "s1", Type: types.ChannelText, Name: "general"}
+	mods    = types.Role{ID: "r1", ServerID: "s1", Name: "Mods"}
+	cats    = types.Server{ID: "s1", Name: "Cats", Channels: []types.Channel{general}, Roles: []types.Role{mods}}
+	alice   = types.Member{User: types.User{ID: "u1", Username: "alice"}, ServerID: "s1"}
+)
+
+func event(eventType types.EventType, platform string, data any) types.Event {
+	return types.Event{Type: eventType, Platform: platform, Data: data}
+}
+
+func TestApplyDisabled(t *testing.T) {
+	s := New(Config{})
+	s.Apply(event(types.EventServerCreate, "discord", cats))
+	s.Apply(event(types.EventMemberJoin, "discord", alice))
+
+	if _, ok := s.Server("discord", "s1"); ok {
+		t.Error("server cached with Servers off")
+	}
+	if _, ok := s.Channel("discord", "c1"); ok {
+		t.Error("channel cached with Channels off")
+	}
+	if roles := s.Roles("discord", "s1"); roles != nil {
+		t.Errorf("roles %v cached with Roles off", roles)
+	}
+	if _, ok := s.Member("discord", "s1", "u1"); ok {
+		t.Error("member cached with Members off")
+	}
+	s.Apply(event(types.EventRoleCreate, "discord", types.Role{ID: "r2", ServerID: "s1"}))
+	if stats := s.Stats(); stats != (Stats{}) {
+		t.Errorf("Stats = %+v, want zero", stats)
+	}
+
+	// Caches are independent of each other.
+	s = New(Config{Channels: true})
+	s.Apply(event(types.EventServerCreate, "discord", cats))
+	if _, ok := s.Server("discord", "s1"); ok {
+		t.Error("server cached with Servers off")
+	}
+	if _, ok := s.Channel("discord", "c1"); !ok {
+		t.Error("channel of a new server not cached")
+	}
+}
+
+func TestApplyIDOnlyUpdates(t *testing.T) {
+	s := New(DefaultConfig)
+	s.Apply(event(types.EventServerCreate, "discord", cats))
+
+	// The platform could not resolve these updates, so they only carry IDs.
+	s.Apply(event(types.EventServerUpdate, "discord", types.Server{ID: "s1"}))
+	s.Apply(event(types.EventChannelUpdate, "discord", types.Channel{ID: "c1"}))
+	s.Apply(event(types.EventChannelUpdate, "discord", types.Channel{ID: "c2"}))
+
+	if server, ok := s.Server("discord", "s1"); !ok || server.Name != "Cats" {
+		t.Errorf("Server = %+v, %v; want Cats kept", server, ok)
+	}
+	if channel, ok := s.Channel("discord", "c1"); !ok || channel != general {
+		t.Errorf("Channel = %+v, %v; want %+v kept", channel, ok, general)
+	}
+	if _, ok := s.Channel("discord", "c2"); ok {
+		t.Error("ID-only channel cached")
+	}
+
+	// Full updates replace the cached entities.
+	s.Apply(event(types.EventServerUpdate, "discord", types.Server{ID: "s1", Name: "Kittens"}))
+	if server, _ := s.Server("discord", "s1"); server.Name != "Kittens" || server.Channels != nil {
+		t.Errorf("Server = %+v, want Kittens without channels", server)
+	}
+	if !slices.Equal(s.Roles("discord", "s1"), []types.Role{mods}) {
+		t.Errorf("Roles = %v, want the roles kept by an update without any", s.Roles("discord", "s1"))
+	}
+}
+
+func TestApplyRoles(t *testing.T) {
+	admins := types.Role{ID: "r2", ServerID: "s1", Name: "Admins"}
+	renamed := types.Role{ID: "r1", ServerID: "s1", Name: "Moderators"}
+
+	tests := []struct {
+		name   string
+		events []types.Event
+		want   []types.Role
+	}{
+		{
+			name:   "create",
+			events: []types.Event{event(types.EventRoleCreate, "discord", admins)},
+			want:   []types.Role{mods, admins},
+		},
+		{
+			name:   "update",
+			events: []types.Event{event(types.EventRoleUpdate, "discord", renamed)},
+			want:   []types.Role{renamed},
+		},
+		{
+			name:   "update of an unknown role",
+			events: []types.Event{event(types.EventRoleUpdate, "discord", admins)},
+			want:   []types.Role{mods, admins},
+		},
+		{
+			name:   "delete",
+			events: []types.Event{event(types.EventRoleDelete, "discord", types.Role{ID: "r1", ServerID: "s1"})},
+			want:   []types.Role{},
+		},
+		{
+			name:   "other platform",
+			events: []types.Event{event(types.EventRoleCreate, "revolt", admins)},
+			want:   []types.Role{mods},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(DefaultConfig)
+			s.Apply(event(types.EventServerCreate, "discord", cats))
+			for _, evt := range tt.events {
+				s.Apply(evt)
+			}
+			if got := s.Roles("discord", "s1"); !slices.Equal(got, tt.want) {
+				t.Errorf("Roles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Roles of servers whose role list is unknown are not cached, since
+	// the list would be incomplete.
+	s := New(DefaultConfig)
+	s.Apply(event(types.EventRoleCreate, "discord", admins))
+	s.Apply(event(types.EventRoleUpdate, "discord", renamed))
+	if roles := s.Roles("discord", "s1"); roles != nil {
+		t.Errorf("Roles = %v for a server whose roles were never cached", roles)
+	}
+	if _, ok := s.Role("discord", "s1", "r2"); ok {
+		t.Error("Role found for a server whose roles were never cached")
+	}
+
+	// Roles returns a copy.
+	s.Apply(event(types.EventServerCreate, "discord", cats))
+	s.Roles("discord", "s1")[0].Name = "changed"
+	if role, _ := s.Role("discord", "s1", "r1"); role != mods {
+		t.Errorf("Role = %+v after changing a returned slice, want %+v", role, mods)
+	}
+}
+
+func TestApplyServerDelete(t *testing.T) {
+	s := New(DefaultConfig)
+	other := types.Server{ID: "s2", Name: "Dogs", Channels: []types.Channel{{ID: "c2", ServerID: "s2", Type: types.ChannelText}}}
+	bob := types.Member{User: types.User{ID: "u2"}, ServerID: "s2"}
+	for _, platform := range []string{"discord", "revolt"} {
+		s.Apply(event(types.EventServerCreate, platform, cats))
+		s.Apply(event(types.EventServerCreate, platform, other))
+		s.Apply(event(types.EventMemberJoin, platform, alice))
+		s.Apply(event(types.EventMemberJoin, platform, bob))
+	}
+
+	s.Apply(event(types.EventServerDelete, "discord", types.Server{ID: "s1"}))
+
+	if _, ok := s.Server("discord", "s1"); ok {
+		t.Error("deleted server still cached")
+	}
+	if _, ok := s.Channel("discord", "c1"); ok {
+		t.Error("channel of the deleted server still cached")
+	}
+	if roles := s.Roles("discord", "s1"); roles != nil {
+		t.Errorf("roles %v of the deleted server still cached", roles)
+	}
+	if _, ok := s.Member("discord", "s1", "u1"); ok {
+		t.Error("member of the deleted server still cached")
+	}
+
+	// Other servers, and the same server ID on other platforms, are kept.
+	if _, ok := s.Channel("discord", "c2"); !ok {
+		t.Error("channel of another server removed")
+	}
+	if _, ok := s.Member("discord", "s2", "u2"); !ok {
+		t.Error("member of another server removed")
+	}
+	if _, ok := s.Server("revolt", "s1"); !ok {
+		t.Error("server on another platform removed")
+	}
+	if _, ok := s.Channel("revolt", "c1"); !ok {
+		t.Error("channel on another platform removed")
+	}
+	if _, ok := s.Member("revolt", "s1", "u1"); !ok {
+		t.Error("member on another platform removed")
+	}
+}
@@ -1,14 +1,56 @@
-package main
+package whiskercat
 
-// Stop both Discord and Revolt clients.
-func Stop() {
-	err := Discord.Close()
-	if err != nil {
-		panic("Error stopping Discord client: " + err.Error())
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Stop stops dispatching new events, waits for queued and in-flight
+// handlers to finish and then disconnects every platform.
+//
+// If ctx is done before the handlers drain, events still queued are
+// discarded, the platforms are still disconnected and ctx's error is
+// included in the result. The returned error
+// joins every failure.
+func (b *Bot) Stop(ctx context.Context) error {
+	b.lifecycleMu.Lock()
+	b.stopped = true
+	b.lifecycleMu.Unlock()
+
+	b.supervisor.stop()
+
+	var errs []error
+
+	drained := make(chan struct{})
+	go func() {
+		b.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("draining handlers: %w", ctx.Err()))
 	}
 
-	err = Revolt.Close()
-	if err != nil {
-		panic("Error stopping Revolt client: " + err.Error())
+	if b.dispatcher != nil {
+		b.dispatcher.close()
+	}
+
+	for name, p := range b.platforms {
+		if err := p.Disconnect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Stop stops the default bot.
+func Stop(ctx context.Context) error {
+	if defaultBot == nil {
+		return nil
 	}
+	return defaultBot.Stop(ctx)
 }
@@ -0,0 +1,222 @@
+package whiskercat
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// ReconnectPolicy controls how the supervisor reconnects a dropped gateway.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration // Delay before the first reconnect attempt
+	MaxDelay     time.Duration // Upper bound for the delay between attempts
+	Multiplier   float64       // Growth factor of the delay after each failure
+	Jitter       float64       // Fraction (0-1) of each delay that is randomized
+	DownAfter    int           // Consecutive failures before a platform is reported Down
+}
+
+// DefaultReconnectPolicy is used unless WithReconnectPolicy is given.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialDelay: time.Second,
+	MaxDelay:     2 * time.Minute,
+	Multiplier:   2,
+	Jitter:       0.5,
+	DownAfter:    5,
+}
+
+// delay returns the backoff before reconnect attempt n (starting at 0).
+func (p ReconnectPolicy) delay(n int) time.Duration {
+	d := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(n))
+	if d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d -= rand.Float64() * d * p.Jitter
+	}
+	return time.Duration(d)
+}
+
+// PlatformHealth is a snapshot of one platform's connection state.
+type PlatformHealth struct {
+	State     types.Health // Healthy, Degraded or Down
+	Since     time.Time    // When State last changed
+	Attempts  int          // Consecutive failed reconnect attempts
+	LastError error        // Most recent connect error, if any
+}
+
+// supervisor watches the connection events of every platform, reconnects
+// dropped gateways and tracks their health.
+type supervisor struct {
+	policy ReconnectPolicy
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu           sync.Mutex
+	health       map[string]*PlatformHealth
+	reconnecting map[string]bool
+	abandoned    map[string]bool // Platforms whose initial connect Start gave up on
+	stopped      bool
+}
+
+func newSupervisor(policy ReconnectPolicy) *supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &supervisor{
+		policy:       policy,
+		ctx:          ctx,
+		cancel:       cancel,
+		health:       make(map[string]*PlatformHealth),
+		reconnecting: make(map[string]bool),
+		abandoned:    make(map[string]bool),
+	}
+}
+
+// setState records a state change for name. Callers must hold s.mu.
+func (s *supervisor) setState(name string, state types.Health, err error) {
+	h, ok := s.health[name]
+	if !ok {
+		h = &PlatformHealth{}
+		s.health[name] = h
+	}
+	if h.State != state {
+		h.State = state
+		h.Since = time.Now()
+	}
+	if err != nil {
+		h.LastError = err
+	}
+}
+
+// started records the outcome of the initial connect made by Start.
+func (s *supervisor) started(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.setState(name, types.Down, err)
+		return
+	}
+	delete(s.abandoned, name)
+	// Connected but not ready until the gateway says so, unless it already
+	// did while connecting.
+	if h, ok := s.health[name]; ok && h.State == types.Healthy {
+		return
+	}
+	s.setState(name, types.Degraded, nil)
+}
+
+// abandon ignores the connection events of a platform whose initial
+// connect Start stopped waiting for, so that connect finishing late
+// neither marks it healthy nor gets it reconnected.
+func (s *supervisor) abandon(name string) {
+	s.mu.Lock()
+	s.abandoned[name] = true
+	s.mu.Unlock()
+}
+
+// observe inspects every event before it is dispatched.
+func (s *supervisor) observe(p platform.Platform, evt types.Event) {
+	switch evt.Type {
+	case types.EventConnected, types.EventResumed:
+		s.mu.Lock()
+		if s.abandoned[p.Name()] {
+			s.mu.Unlock()
+			return
+		}
+		s.setState(p.Name(), types.Healthy, nil)
+		s.health[p.Name()].Attempts = 0
+		s.mu.Unlock()
+
+	case types.EventDisconnected:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.stopped || s.reconnecting[p.Name()] || s.abandoned[p.Name()] {
+			return
+		}
+		s.setState(p.Name(), types.Degraded, nil)
+		s.reconnecting[p.Name()] = true
+		go s.reconnect(p)
+	}
+}
+
+// reconnect retries Connect with exponential backoff and jitter until it
+// succeeds or the supervisor is stopped.
+func (s *supervisor) reconnect(p platform.Platform) {
+	name := p.Name()
+
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-time.After(s.policy.delay(attempt)):
+		case <-s.ctx.Done():
+			return
+		}
+
+		err := p.Connect(s.ctx)
+
+		s.mu.Lock()
+		if err == nil {
+			s.reconnecting[name] = false
+			s.mu.Unlock()
+			log.Printf("✅ %s reconnected.\n", name)
+			return
+		}
+
+		h := s.health[name]
+		h.Attempts++
+		state := types.Degraded
+		if h.Attempts >= s.policy.DownAfter {
+			state = types.Down
+		}
+		s.setState(name, state, err)
+		s.mu.Unlock()
+
+		log.Printf("❌ %s reconnect attempt %d failed: %v\n", name, attempt+1, err)
+	}
+}
+
+// stop ends every reconnect loop and ignores further disconnects.
+func (s *supervisor) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	for name := range s.health {
+		s.setState(name, types.Down, nil)
+	}
+	s.mu.Unlock()
+
+	s.cancel()
+}
+
+// Health returns the connection state of the named platform. Unknown or
+// never-started platforms are reported as Down.
+func (b *Bot) Health(name string) types.Health {
+	b.supervisor.mu.Lock()
+	defer b.supervisor.mu.Unlock()
+
+	if h, ok := b.supervisor.health[name]; ok {
+		return h.State
+	}
+	return types.Down
+}
+
+// HealthReport returns a snapshot of every platform's connection state.
+func (b *Bot) HealthReport() map[string]PlatformHealth {
+	b.supervisor.mu.Lock()
+	defer b.supervisor.mu.Unlock()
+
+	report := make(map[string]PlatformHealth, len(b.platforms))
+	for name := range b.platforms {
+		if h, ok := b.supervisor.health[name]; ok {
+			report[name] = *h
+		} else {
+			report[name] = PlatformHealth{State: types.Down}
+		}
+	}
+	return report
+}
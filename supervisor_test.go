@@ -0,0 +1,41 @@
+package whiskercat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	policy := ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, Multiplier: 2}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 10 * time.Millisecond},
+		{1, 20 * time.Millisecond},
+		{2, 40 * time.Millisecond},
+		{3, 50 * time.Millisecond},
+		{30, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := policy.delay(tt.attempt); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestReconnectDelayJitter(t *testing.T) {
+	policy := ReconnectPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2, Jitter: 0.5}
+	seen := make(map[time.Duration]bool)
+	for range 100 {
+		// Jitter shortens the delay by up to half.
+		d := policy.delay(1)
+		if d < 100*time.Millisecond || d > 200*time.Millisecond {
+			t.Fatalf("delay(1) = %v, want between 100ms and 200ms", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Error("jittered delays are all the same")
+	}
+}
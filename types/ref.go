@@ -0,0 +1,198 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// References identify an entity together with the platform it lives on, so
+// IDs can be stored and later used without tracking the platform
+// separately. Their string form is the platform name and the ID separated
+// by a colon, such as "discord:123"; a MessageRef adds the message ID after
+// a slash, as in "discord:123/456".
+//
+// References hold platform names in lower case, whether they are parsed or
+// built from an event, so they can be compared and used as map keys. Use
+// PlatformKey when writing a reference as a literal.
+
+// ErrInvalidRef is returned when parsing a malformed reference.
+var ErrInvalidRef = errors.New("invalid reference")
+
+// UserRef identifies a user.
+type UserRef struct {
+	Platform string
+	ID       string
+}
+
+// ChannelRef identifies a channel.
+type ChannelRef struct {
+	Platform string
+	ID       string
+}
+
+// ServerRef identifies a server.
+type ServerRef struct {
+	Platform string
+	ID       string
+}
+
+// MessageRef identifies a message in a channel.
+type MessageRef struct {
+	Channel ChannelRef
+	ID      string
+}
+
+// PlatformKey returns the spelling of a platform name used in references,
+// such as "discord" for "Discord".
+func PlatformKey(name string) string {
+	return strings.ToLower(name)
+}
+
+func formatRef(platform, id string) string {
+	return PlatformKey(platform) + ":" + id
+}
+
+func parseRef(kind, s string) (platform, id string, err error) {
+	platform, id, ok := strings.Cut(s, ":")
+	if !ok || platform == "" || id == "" {
+		return "", "", fmt.Errorf("%w: %s %q", ErrInvalidRef, kind, s)
+	}
+	return PlatformKey(platform), id, nil
+}
+
+// String returns the reference as "platform:id".
+func (r UserRef) String() string { return formatRef(r.Platform, r.ID) }
+
+// String returns the reference as "platform:id".
+func (r ChannelRef) String() string { return formatRef(r.Platform, r.ID) }
+
+// String returns the reference as "platform:id".
+func (r ServerRef) String() string { return formatRef(r.Platform, r.ID) }
+
+// String returns the reference as "platform:channel/message".
+func (r MessageRef) String() string { return r.Channel.String() + "/" + r.ID }
+
+// IsZero reports whether r is unset.
+func (r UserRef) IsZero() bool { return r == UserRef{} }
+
+// IsZero reports whether r is unset.
+func (r ChannelRef) IsZero() bool { return r == ChannelRef{} }
+
+// IsZero reports whether r is unset.
+func (r ServerRef) IsZero() bool { return r == ServerRef{} }
+
+// IsZero reports whether r is unset.
+func (r MessageRef) IsZero() bool { return r == MessageRef{} }
+
+// ParseUserRef parses the string form of a UserRef.
+func ParseUserRef(s string) (UserRef, error) {
+	platform, id, err := parseRef("user", s)
+	return UserRef{Platform: platform, ID: id}, err
+}
+
+// ParseChannelRef parses the string form of a ChannelRef.
+func ParseChannelRef(s string) (ChannelRef, error) {
+	platform, id, err := parseRef("channel", s)
+	return ChannelRef{Platform: platform, ID: id}, err
+}
+
+// ParseServerRef parses the string form of a ServerRef.
+func ParseServerRef(s string) (ServerRef, error) {
+	platform, id, err := parseRef("server", s)
+	return ServerRef{Platform: platform, ID: id}, err
+}
+
+// ParseMessageRef parses the string form of a MessageRef. The channel ID
+// ends at the first slash, since message IDs may contain slashes.
+func ParseMessageRef(s string) (MessageRef, error) {
+	platform, rest, err := parseRef("message", s)
+	if err != nil {
+		return MessageRef{}, err
+	}
+	channelID, id, ok := strings.Cut(rest, "/")
+	if !ok || channelID == "" || id == "" {
+		return MessageRef{}, fmt.Errorf("%w: message %q", ErrInvalidRef, s)
+	}
+	return MessageRef{Channel: ChannelRef{Platform: platform, ID: channelID}, ID: id}, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r UserRef) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (r ChannelRef) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (r ServerRef) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (r MessageRef) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *UserRef) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseUserRef(string(text))
+	return err
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *ChannelRef) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseChannelRef(string(text))
+	return err
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *ServerRef) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseServerRef(string(text))
+	return err
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *MessageRef) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseMessageRef(string(text))
+	return err
+}
+
+// ChannelRef returns a reference to the event's channel, which is zero if
+// the event has none.
+func (e Event) ChannelRef() ChannelRef {
+	if e.ChannelID == "" {
+		return ChannelRef{}
+	}
+	return ChannelRef{Platform: PlatformKey(e.Platform), ID: e.ChannelID}
+}
+
+// ServerRef returns a reference to the event's server, which is zero if
+// the event has none.
+func (e Event) ServerRef() ServerRef {
+	if e.ServerID == "" {
+		return ServerRef{}
+	}
+	return ServerRef{Platform: PlatformKey(e.Platform), ID: e.ServerID}
+}
+
+// MessageRef returns a reference to the message a message or reaction
+// event is about.
+func (e Event) MessageRef() (MessageRef, bool) {
+	var id string
+	switch data := e.Data.(type) {
+	case MessageCallback:
+		id = data.ID
+	case ReactionCallback:
+		id = data.MessageID
+	}
+	if id == "" || e.ChannelID == "" {
+		return MessageRef{}, false
+	}
+	return MessageRef{Channel: e.ChannelRef(), ID: id}, true
+}
+
+// Ref returns a reference to the user on platform.
+func (u User) Ref(platform string) UserRef {
+	return UserRef{Platform: PlatformKey(platform), ID: u.ID}
+}
+
+// Ref returns a reference to the sent message.
+func (m *SentMessage) Ref() MessageRef {
+	return MessageRef{Channel: ChannelRef{Platform: PlatformKey(m.Platform), ID: m.ChannelID}, ID: m.ID}
+}
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ChannelRef
+	}{
+		{"discord:123", ChannelRef{Platform: "discord", ID: "123"}},
+		{"Discord:123", ChannelRef{Platform: "discord", ID: "123"}},
+		// Matrix IDs contain colons; the platform ends at the first one.
+		{"matrix:!room:example.org", ChannelRef{Platform: "matrix", ID: "!room:example.org"}},
+		{"irc:#cats", ChannelRef{Platform: "irc", ID: "#cats"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChannelRef(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseChannelRef(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseMessageRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want MessageRef
+	}{
+		{"discord:123/456", MessageRef{Channel: ChannelRef{Platform: "discord", ID: "123"}, ID: "456"}},
+		{"matrix:!room:example.org/$event:example.org", MessageRef{
+			Channel: ChannelRef{Platform: "matrix", ID: "!room:example.org"},
+			ID:      "$event:example.org",
+		}},
+		// Message IDs may contain slashes; the channel ends at the first one.
+		{"revolt:abc/def/ghi", MessageRef{Channel: ChannelRef{Platform: "revolt", ID: "abc"}, ID: "def/ghi"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseMessageRef(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseMessageRef(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+		if s := got.String(); s != tt.in {
+			t.Errorf("String() = %q, want %q", s, tt.in)
+		}
+	}
+}
+
+func TestParseRefMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) error
+		in    string
+	}{
+		{"empty", parseError(ParseUserRef), ""},
+		{"no colon", parseError(ParseUserRef), "discord"},
+		{"no platform", parseError(ParseChannelRef), ":123"},
+		{"no ID", parseError(ParseServerRef), "discord:"},
+		{"message without slash", parseError(ParseMessageRef), "discord:123"},
+		{"message without channel", parseError(ParseMessageRef), "discord:/456"},
+		{"message without ID", parseError(ParseMessageRef), "discord:123/"},
+	}
+	for _, tt := range tests {
+		if err := tt.parse(tt.in); !errors.Is(err, ErrInvalidRef) {
+			t.Errorf("%s: parsing %q: err = %v, want ErrInvalidRef", tt.name, tt.in, err)
+		}
+	}
+}
+
+func parseError[T any](parse func(string) (T, error)) func(string) error {
+	return func(s string) error {
+		_, err := parse(s)
+		return err
+	}
+}
+
+func TestRefRoundTrip(t *testing.T) {
+	evt := Event{
+		Platform:  "Discord",
+		ChannelID: "123",
+		ServerID:  "9",
+		Data:      MessageCallback{ID: "456"},
+	}
+	msg, ok := evt.MessageRef()
+	if !ok {
+		t.Fatal("MessageRef reported no message")
+	}
+	sent := &SentMessage{ID: "456", ChannelID: "123", Platform: "Discord"}
+
+	tests := []struct {
+		name  string
+		built any
+		parse func(string) (any, error)
+		s     string
+	}{
+		{"channel", evt.ChannelRef(), func(s string) (any, error) { return ParseChannelRef(s) }, "discord:123"},
+		{"server", evt.ServerRef(), func(s string) (any, error) { return ParseServerRef(s) }, "discord:9"},
+		{"message", msg, func(s string) (any, error) { return ParseMessageRef(s) }, "discord:123/456"},
+		{"sent message", sent.Ref(), func(s string) (any, error) { return ParseMessageRef(s) }, "discord:123/456"},
+		{"user", User{ID: "7"}.Ref("Discord"), func(s string) (any, error) { return ParseUserRef(s) }, "discord:7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := tt.parse(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// References built from events equal parsed ones.
+			if parsed != tt.built {
+				t.Errorf("built %+v, parsed %+v", tt.built, parsed)
+			}
+			if s := tt.built.(interface{ String() string }).String(); s != tt.s {
+				t.Errorf("String() = %q, want %q", s, tt.s)
+			}
+		})
+	}
+}
+
+func TestRefText(t *testing.T) {
+	type saved struct {
+		Log     ChannelRef            `json:"log"`
+		Message MessageRef            `json:"message"`
+		Owners  map[UserRef]ServerRef `json:"owners"`
+	}
+	in := saved{
+		Log:     ChannelRef{Platform: "matrix", ID: "!room:example.org"},
+		Message: MessageRef{Channel: ChannelRef{Platform: "discord", ID: "1"}, ID: "2"},
+		Owners:  map[UserRef]ServerRef{{Platform: "revolt", ID: "u"}: {Platform: "revolt", ID: "s"}},
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"log":"matrix:!room:example.org","message":"discord:1/2","owners":{"revolt:u":"revolt:s"}}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	var out saved
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Log != in.Log || out.Message != in.Message || out.Owners[UserRef{Platform: "revolt", ID: "u"}] != in.Owners[UserRef{Platform: "revolt", ID: "u"}] {
+		t.Errorf("Unmarshal = %+v, want %+v", out, in)
+	}
+
+	var ref ChannelRef
+	if err := json.Unmarshal([]byte(`"nocolon"`), &ref); !errors.Is(err, ErrInvalidRef) {
+		t.Errorf("Unmarshal of a malformed reference: err = %v, want ErrInvalidRef", err)
+	}
+}
@@ -1,39 +1,72 @@
 package types
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 // EventType defines supported platform event types.
 type EventType string
 
 const (
-	MessageCreate          EventType = "MessageCreate"
-	MessageUpdate          EventType = "MessageUpdate"
-	MessageDelete          EventType = "MessageDelete"
-	ReactionAdd            EventType = "ReactionAdd"
-	ReactionRemove         EventType = "ReactionRemove"
-	InteractionCreate      EventType = "InteractionCreate"
-	EventTypingStart       EventType = "TypingStart"
-	EventVoiceStateUpdate  EventType = "VoiceStateUpdate"
-	EventPresenceUpdate    EventType = "PresenceUpdate"
-	EventGuildMemberAdd    EventType = "GuildMemberAdd"
+	MessageCreate         EventType = "MessageCreate"
+	MessageUpdate         EventType = "MessageUpdate"
+	MessageDelete         EventType = "MessageDelete"
+	ReactionAdd           EventType = "ReactionAdd"
+	ReactionRemove        EventType = "ReactionRemove"
+	InteractionCreate     EventType = "InteractionCreate"
+	EventTypingStart      EventType = "TypingStart"
+	EventVoiceStateUpdate EventType = "VoiceStateUpdate"
+	EventPresenceUpdate   EventType = "PresenceUpdate"
+	EventChannelCreate    EventType = "ChannelCreate"
+	EventChannelUpdate    EventType = "ChannelUpdate"
+	EventChannelDelete    EventType = "ChannelDelete"
+	EventUserUpdate       EventType = "UserUpdate"
+	EventServerCreate     EventType = "ServerCreate"
+	EventServerUpdate     EventType = "ServerUpdate"
+	EventServerDelete     EventType = "ServerDelete"
+	EventRoleCreate       EventType = "RoleCreate"
+	EventRoleUpdate       EventType = "RoleUpdate"
+	EventRoleDelete       EventType = "RoleDelete"
+	EventThreadCreate     EventType = "ThreadCreate" // Discord only
+	EventThreadUpdate     EventType = "ThreadUpdate" // Discord only
+	EventThreadDelete     EventType = "ThreadDelete" // Discord only
+	EventMemberJoin       EventType = "MemberJoin"   // A member joined a server or IRC channel
+	EventMemberLeave      EventType = "MemberLeave"  // A member left or was removed
+	EventMemberUpdate     EventType = "MemberUpdate" // Nickname, roles or timeout changed
+	EventConnected        EventType = "Connected"    // Gateway connected and ready
+	EventDisconnected     EventType = "Disconnected" // Gateway connection lost
+	EventResumed          EventType = "Resumed"      // Gateway session resumed
+)
+
+// Event types that are no longer emitted.
+const (
+	// Deprecated: Discord member joins are reported as EventMemberJoin.
+	EventGuildMemberAdd EventType = "GuildMemberAdd"
+
+	// Deprecated: Discord member leaves are reported as EventMemberLeave.
 	EventGuildMemberRemove EventType = "GuildMemberRemove"
-	EventChannelCreate     EventType = "ChannelCreate"
-	EventChannelUpdate     EventType = "ChannelUpdate"
-	EventChannelDelete     EventType = "ChannelDelete"
-	EventUserUpdate        EventType = "UserUpdate"
-	EventMemberJoin        EventType = "MemberJoin"  // Revolt specific
-	EventMemberLeave       EventType = "MemberLeave" // Revolt specific
 )
 
 // Event represents a normalized platform event.
 type Event struct {
-	Name     string    // Optional identifier for the event
-	Type     EventType // The type of event triggered
-	Platform string    // "Discord" or "Revolt"
-	Bot      bool      // True if the event was triggered by a bot
-	Context  any       // The raw platform event (e.g., *discordgo.MessageCreate)
-	Session  any       // The session for the platform
-	Data     any       // Parsed payload like MessageCallback or InteractionCallback
+	Name      string    // Optional identifier for the event
+	Type      EventType // The type of event triggered
+	Platform  string    // Platform name, e.g. "Discord", "Revolt" or "Telegram"
+	Bot       bool      // True if the event was triggered by a bot
+	Self      bool      // True if the bot itself triggered the event, including messages sent with a Masquerade (Discord, Revolt and Matrix)
+	Context   any       // The raw platform event (e.g., *discordgo.MessageCreate)
+	Session   any       // The session for the platform
+	Data      any       // Parsed payload like MessageCallback or InteractionCallback
+	ChannelID string    // Channel the event happened in, if any
+	ServerID  string    // Guild/server the event happened in, if any
+	Source    Responder // The platform adapter that produced the event
+}
+
+// Responder replies to events on the platform that produced them.
+type Responder interface {
+	Respond(e Event, msg MessageSend, edit *string) (*SentMessage, error)
 }
 
 // ActivityType describes what the bot is shown doing.
@@ -59,22 +92,84 @@ const (
 	Invisible Presence = "Invisible"
 )
 
+// Health describes the connection state of a platform.
+type Health string
+
+const (
+	Healthy  Health = "Healthy"  // Connected and ready
+	Degraded Health = "Degraded" // Disconnected and reconnecting
+	Down     Health = "Down"     // Not connected and reconnecting has kept failing
+)
+
 // DiscordConfig contains Discord credentials.
 type DiscordConfig struct {
 	ClientID     string // OAuth2 Client ID
 	ClientSecret string // OAuth2 Client Secret
 	Token        string // Bot Token
+	Disabled     bool   // Skip Discord even though it is configured
 }
 
+// Enabled reports whether Discord is configured and not disabled.
+func (c *DiscordConfig) Enabled() bool { return c != nil && !c.Disabled }
+
 // RevoltConfig contains Revolt credentials.
 type RevoltConfig struct {
-	Token string // Bot Token
+	Token    string // Bot Token
+	Disabled bool   // Skip Revolt even though it is configured
 }
 
+// Enabled reports whether Revolt is configured and not disabled.
+func (c *RevoltConfig) Enabled() bool { return c != nil && !c.Disabled }
+
+// MatrixConfig contains Matrix credentials.
+type MatrixConfig struct {
+	Homeserver  string // Base URL of the homeserver (e.g. https://matrix.org)
+	UserID      string // Full user ID of the bot (e.g. @whiskercat:matrix.org)
+	AccessToken string // Access token of the bot account
+	Disabled    bool   // Skip Matrix even though it is configured
+}
+
+// Enabled reports whether Matrix is configured and not disabled.
+func (c *MatrixConfig) Enabled() bool { return c != nil && !c.Disabled }
+
+// TelegramConfig contains Telegram Bot API credentials.
+type TelegramConfig struct {
+	Token    string // Bot token from @BotFather
+	APIURL   string // Bot API base URL; defaults to https://api.telegram.org
+	Disabled bool   // Skip Telegram even though it is configured
+}
+
+// Enabled reports whether Telegram is configured and not disabled.
+func (c *TelegramConfig) Enabled() bool { return c != nil && !c.Disabled }
+
+// IRCConfig contains IRC connection settings.
+type IRCConfig struct {
+	Server             string        // Address of the server (e.g. irc.libera.chat:6697)
+	TLS                bool          // Connect with TLS
+	InsecureSkipVerify bool          // Accept any TLS certificate (for self-signed servers)
+	Password           string        // Server password sent with PASS, if any
+	Nick               string        // Nickname of the bot
+	Username           string        // Username (ident); defaults to Nick
+	RealName           string        // Real name; defaults to Nick
+	SASLUser           string        // Account for SASL PLAIN authentication, if any
+	SASLPassword       string        // Password for SASL PLAIN authentication
+	NickServPassword   string        // Password to IDENTIFY with NickServ after connecting, if any
+	Channels           []string      // Channels to join (and rejoin after kicks and reconnects)
+	FloodDelay         time.Duration // Delay between lines after a short burst; defaults to 1s
+	Disabled           bool          // Skip IRC even though it is configured
+}
+
+// Enabled reports whether IRC is configured and not disabled.
+func (c *IRCConfig) Enabled() bool { return c != nil && !c.Disabled }
+
 // AuthConfig aggregates credentials for all platforms.
+// A nil platform section leaves that platform unconfigured.
 type AuthConfig struct {
-	Discord DiscordConfig // Discord configuration
-	Revolt  RevoltConfig  // Revolt configuration
+	Discord  *DiscordConfig  // Discord configuration
+	Revolt   *RevoltConfig   // Revolt configuration
+	Matrix   *MatrixConfig   // Matrix configuration
+	Telegram *TelegramConfig // Telegram configuration
+	IRC      *IRCConfig      // IRC configuration
 }
 
 // User represents a basic user identity.
@@ -84,18 +179,116 @@ type User struct {
 	Avatar   string // Avatar URL or identifier
 }
 
-// MessageCallback wraps a received message and author.
+// Server is a Discord guild or Revolt server.
+type Server struct {
+	ID      string // Server ID
+	Name    string // Server name
+	Icon    string // Icon URL
+	OwnerID string // User ID of the owner
+
+	// Channels are only filled in by ServerCreate and Roles by ServerCreate
+	// and ServerUpdate. Other events leave them nil.
+	Channels []Channel
+	Roles    []Role
+}
+
+// ChannelType is the kind of a Channel.
+type ChannelType string
+
+const (
+	ChannelText     ChannelType = "Text"
+	ChannelVoice    ChannelType = "Voice"
+	ChannelCategory ChannelType = "Category"
+	ChannelThread   ChannelType = "Thread"
+	ChannelDM       ChannelType = "DM"
+	ChannelGroup    ChannelType = "Group" // Group DM
+	ChannelOther    ChannelType = "Other"
+)
+
+// Channel is a server channel, thread or direct message channel.
+type Channel struct {
+	ID       string      // Channel ID
+	ServerID string      // Server the channel belongs to; empty for DMs
+	ParentID string      // Category of a channel, or channel of a thread
+	Type     ChannelType // Kind of channel
+	Name     string      // Channel name
+	Topic    string      // Topic or description
+	NSFW     bool        // Whether the channel is age-restricted
+}
+
+// Role is a server role.
+type Role struct {
+	ID          string // Role ID
+	ServerID    string // Server the role belongs to
+	Name        string // Role name
+	Color       int    // Color as integer (hex); 0 if unset
+	Position    int    // Position in the role list; higher ranks first on Discord, lower on Revolt
+	Hoist       bool   // Whether members are listed separately
+	Permissions int64  // Platform-specific permission bits
+}
+
+// MessageCallback is a received message, normalized across platforms.
 type MessageCallback struct {
-	Content string // Message content
-	Author  User   // Message author
+	ID        string    // Message ID
+	ChannelID string    // Channel the message was sent in
+	ServerID  string    // Server (guild) the channel belongs to; empty in DMs
+	Content   string    // Message content
+	Author    User      // Message author
+	CreatedAt time.Time // When the message was sent
+	EditedAt  time.Time // When the message was last edited; zero if never
+	ReplyTo   string    // ID of the message this one replies to, if any
+	Mentions  Mentions  // Users, roles and channels mentioned in the message
+	DM        bool      // Whether the message was sent in a direct message
+}
+
+// Mentions lists the IDs mentioned in a message.
+type Mentions struct {
+	Users    []string // Mentioned user IDs
+	Roles    []string // Mentioned role IDs
+	Channels []string // Mentioned channel IDs
+}
+
+// Member is a user's membership of a server.
+type Member = MemberCallback
+
+// MemberCallback is a member joining, leaving or being updated.
+type MemberCallback struct {
+	User     User      // The member's user
+	ServerID string    // Server of the membership; empty for IRC channels
+	JoinedAt time.Time // When the member joined; zero if unknown
+	Roles    []string  // Role IDs, if known
+}
+
+// ReactionCallback is a reaction added to or removed from a message.
+type ReactionCallback struct {
+	MessageID string // Message reacted to
+	ChannelID string // Channel of the message
+	ServerID  string // Server (guild) of the channel; empty in DMs
+	User      User   // User who reacted; only the ID may be known
+	Emoji     Emoji  // Reaction emoji
+}
+
+// Emoji is a unicode or custom emoji.
+type Emoji struct {
+	Name     string // The unicode emoji itself, or the custom emoji's name
+	ID       string // Custom emoji ID; empty for unicode emoji
+	Animated bool   // Whether the custom emoji is animated
+}
+
+// Custom reports whether e is a custom emoji rather than a unicode one.
+func (e Emoji) Custom() bool {
+	return e.ID != ""
 }
 
 // InteractionCallback holds interaction data like slash commands.
 type InteractionCallback struct {
 	Name   string                       // Name of the interaction/command
-	Fields map[string]string            // Option key-value map
+	Fields map[string]string            // Option key-value map; nil for commands typed as text
+	Args   string                       // Unparsed arguments of commands typed as text, such as Telegram's
 	Data   *discordgo.InteractionCreate // Raw interaction object (Discord only)
 	Author User                         // Command invoker
+	DM     bool                         // Whether the interaction happened in a direct message
+	Button bool                         // Whether a button was pressed; Name is then its callback data
 }
 
 // Embed defines a structured rich message.
@@ -109,6 +302,7 @@ type Embed struct {
 	Footer      *EmbedFooter  // Footer text
 	Color       int           // Accent color as integer (hex)
 }
+
 type EmbedFooter struct {
 	Text     string // Footer text
 	PhotoURL string // Footer Photo URL
@@ -118,3 +312,27 @@ type EmbedField struct {
 	Value  string // Field value
 	Inline bool   // Whether to display inline
 }
+
+// MessageSend describes an outgoing message.
+type MessageSend struct {
+	Content    string      // Message content
+	Embed      *Embed      // Optional embed
+	Ephemeral  bool        // Show a response only to the user who invoked it (Discord interactions only)
+	ReplyTo    string      // Message in the same channel to reply to, if any
+	Masquerade *Masquerade // Name and avatar to show instead of the bot's (Discord and Revolt)
+}
+
+// Masquerade shows a message as sent by someone else, such as the author of
+// a message relayed from another platform.
+type Masquerade struct {
+	Name   string // Display name
+	Avatar string // Avatar URL
+}
+
+// SentMessage identifies a message the bot has sent or edited.
+type SentMessage struct {
+	ID        string // Message ID
+	ChannelID string // Channel the message lives in
+	Platform  string // Platform name, e.g. "Discord"
+	Raw       any    // The raw platform message (e.g., *discordgo.Message)
+}
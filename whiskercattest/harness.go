@@ -0,0 +1,161 @@
+package whiskercattest
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/platform"
+)
+
+// Harness is a started bot connected only to a fake platform. Inject
+// events through the embedded Platform and check the bot's reaction with
+// the Assert methods.
+type Harness struct {
+	*Platform
+	Bot *whiskercat.Bot
+
+	t testing.TB
+}
+
+// New starts a bot with opts on a fake platform named Name and stops it
+// when the test ends.
+//
+// Handlers run inline, so each Inject call returns only after the handlers
+// are done and assertions can follow it directly. Pass WithDispatch in
+// opts to test with a worker pool instead.
+func New(t testing.TB, opts ...whiskercat.Option) *Harness {
+	t.Helper()
+
+	p := NewPlatform(Name)
+	opts = append([]whiskercat.Option{whiskercat.WithDispatch(whiskercat.DispatchConfig{})}, opts...)
+	b := whiskercat.NewWithPlatforms([]platform.Platform{p}, opts...)
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("whiskercattest: starting bot: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := b.Stop(ctx); err != nil {
+			t.Errorf("whiskercattest: stopping bot: %v", err)
+		}
+	})
+
+	return &Harness{Platform: p, Bot: b, t: t}
+}
+
+// Sent returns the sends and edits, in order.
+func (h *Harness) Sent() []Action {
+	var sent []Action
+	for _, a := range h.Actions() {
+		if a.Kind == ActionSend || a.Kind == ActionEdit {
+			sent = append(sent, a)
+		}
+	}
+	return sent
+}
+
+// AssertSent fails the test unless a message with exactly content was
+// sent or edited, and returns the first such action.
+func (h *Harness) AssertSent(content string) Action {
+	h.t.Helper()
+	return h.assertSent("content "+strconv.Quote(content), func(a Action) bool {
+		return a.Message.Content == content
+	})
+}
+
+// AssertSentContains fails the test unless a sent or edited message
+// contains substr, and returns the first such action.
+func (h *Harness) AssertSentContains(substr string) Action {
+	h.t.Helper()
+	return h.assertSent("content containing "+strconv.Quote(substr), func(a Action) bool {
+		return strings.Contains(a.Message.Content, substr)
+	})
+}
+
+// AssertEmbed fails the test unless a sent or edited message has an embed
+// titled title, and returns the first such action.
+func (h *Harness) AssertEmbed(title string) Action {
+	h.t.Helper()
+	return h.assertSent("embed titled "+strconv.Quote(title), func(a Action) bool {
+		return a.Message.Embed != nil && a.Message.Embed.Title == title
+	})
+}
+
+// AssertReplied fails the test unless a response replied to m.
+func (h *Harness) AssertReplied(m *Message) Action {
+	h.t.Helper()
+	return h.assertSent("reply to "+m.ID, func(a Action) bool {
+		return a.ReplyTo == m.ID
+	})
+}
+
+func (h *Harness) assertSent(want string, match func(Action) bool) Action {
+	h.t.Helper()
+
+	sent := h.Sent()
+	for _, a := range sent {
+		if match(a) {
+			return a
+		}
+	}
+	h.t.Fatalf("whiskercattest: no message with %s was sent; sent:\n%s", want, describe(sent))
+	return Action{}
+}
+
+// AssertReacted fails the test unless the bot reacted to messageID with emoji.
+func (h *Harness) AssertReacted(messageID, emoji string) {
+	h.t.Helper()
+	for _, a := range h.Actions() {
+		if a.Kind == ActionReact && a.MessageID == messageID && a.Emoji == emoji {
+			return
+		}
+	}
+	h.t.Fatalf("whiskercattest: no %s reaction on %s; actions:\n%s", emoji, messageID, describe(h.Actions()))
+}
+
+// AssertDeleted fails the test unless the bot deleted messageID.
+func (h *Harness) AssertDeleted(messageID string) {
+	h.t.Helper()
+	for _, a := range h.Actions() {
+		if a.Kind == ActionDelete && a.MessageID == messageID {
+			return
+		}
+	}
+	h.t.Fatalf("whiskercattest: %s was not deleted; actions:\n%s", messageID, describe(h.Actions()))
+}
+
+// AssertNoActions fails the test if the bot did anything.
+func (h *Harness) AssertNoActions() {
+	h.t.Helper()
+	if actions := h.Actions(); len(actions) > 0 {
+		h.t.Fatalf("whiskercattest: expected no actions, got:\n%s", describe(actions))
+	}
+}
+
+func describe(actions []Action) string {
+	if len(actions) == 0 {
+		return "\t(none)"
+	}
+	var b strings.Builder
+	for _, a := range actions {
+		b.WriteString("\t" + string(a.Kind) + " " + a.ChannelID)
+		if a.MessageID != "" {
+			b.WriteString("/" + a.MessageID)
+		}
+		switch {
+		case a.Kind == ActionReact:
+			b.WriteString(" " + a.Emoji)
+		case a.Message.Content != "":
+			b.WriteString(" " + strconv.Quote(a.Message.Content))
+		}
+		if a.Message.Embed != nil {
+			b.WriteString(" [embed " + strconv.Quote(a.Message.Embed.Title) + "]")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,125 @@
+package whiskercattest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// recorder is a testing.TB that records failures instead of stopping the
+// test, so failing assertions can be checked.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// ping returns a handler that answers "ping" and "embed", reacts to
+// "react" and deletes "delete".
+func ping(b *whiskercat.Bot) func(types.Event, types.MessageCallback) {
+	return func(evt types.Event, msg types.MessageCallback) {
+		switch msg.Content {
+		case "ping":
+			whiskercat.Respond(evt, "Pong!", nil, nil)
+		case "embed":
+			whiskercat.Respond(evt, "", &types.Embed{Title: "Status"}, nil)
+		case "react":
+			b.AddReaction(evt.Platform, evt.ChannelID, msg.ID, "👍")
+		case "delete":
+			b.DeleteMessage(evt.Platform, evt.ChannelID, msg.ID)
+		}
+	}
+}
+
+func TestHarness(t *testing.T) {
+	h := New(t)
+	h.Bot.OnMessageCreate(ping(h.Bot))
+
+	m := h.InjectMessage("general", User("alice"), "ping")
+	h.AssertSent("Pong!")
+	h.AssertSentContains("Pong")
+	h.AssertReplied(m)
+
+	h.InjectMessage("general", User("alice"), "embed")
+	h.AssertEmbed("Status")
+
+	m = h.InjectMessage("general", User("alice"), "react")
+	h.AssertReacted(m.ID, "👍")
+
+	m = h.InjectMessage("general", User("alice"), "delete")
+	h.AssertDeleted(m.ID)
+
+	if sent := h.Sent(); len(sent) != 2 {
+		t.Errorf("Sent returned %d actions, want the 2 messages", len(sent))
+	}
+
+	h.Reset()
+	h.InjectMessage("general", User("alice"), "hello")
+	h.AssertNoActions()
+}
+
+func TestHarnessFailures(t *testing.T) {
+	h := New(t)
+	h.Bot.OnMessageCreate(ping(h.Bot))
+	m := h.InjectMessage("general", User("alice"), "ping")
+	other := &Message{ID: "other"}
+
+	tests := []struct {
+		name   string
+		assert func()
+		want   string
+	}{
+		{"sent", func() { h.AssertSent("Pong") }, `no message with content "Pong" was sent`},
+		{"contains", func() { h.AssertSentContains("Ping") }, `no message with content containing "Ping"`},
+		{"embed", func() { h.AssertEmbed("Status") }, `no message with embed titled "Status"`},
+		{"replied", func() { h.AssertReplied(other) }, "no message with reply to other"},
+		{"reacted", func() { h.AssertReacted(m.ID, "👍") }, "no 👍 reaction on " + m.ID},
+		{"deleted", func() { h.AssertDeleted(m.ID) }, m.ID + " was not deleted"},
+		{"no actions", func() { h.AssertNoActions() }, "expected no actions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			h.t = r
+			defer func() { h.t = t }()
+
+			tt.assert()
+
+			if len(r.failures) != 1 {
+				t.Fatalf("assertion reported %d failures, want 1", len(r.failures))
+			}
+			if !strings.Contains(r.failures[0], tt.want) {
+				t.Errorf("failure %q does not mention %q", r.failures[0], tt.want)
+			}
+			// The sent message is listed to help debugging.
+			if tt.name != "reacted" && tt.name != "deleted" && !strings.Contains(r.failures[0], `"Pong!"`) {
+				t.Errorf("failure %q does not list what was sent", r.failures[0])
+			}
+		})
+	}
+}
+
+func TestHarnessWithDispatch(t *testing.T) {
+	done := make(chan struct{})
+	h := New(t, whiskercat.WithDispatch(whiskercat.DispatchConfig{Workers: 2, QueueSize: 4}))
+	h.Bot.OnMessageCreate(func(evt types.Event, msg types.MessageCallback) {
+		ping(h.Bot)(evt, msg)
+		close(done)
+	})
+
+	h.InjectMessage("general", User("alice"), "ping")
+	<-done
+	h.AssertSent("Pong!")
+}
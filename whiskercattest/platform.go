@@ -0,0 +1,441 @@
+// Package whiskercattest provides an in-memory fake platform and a test
+// harness for code written against OnEvent and Respond, so command logic
+// can be tested offline and deterministically.
+//
+//	func TestPing(t *testing.T) {
+//		h := whiskercattest.New(t)
+//		h.Bot.OnEvent(myHandler)
+//
+//		h.InjectMessage("general", whiskercattest.User("alice"), "ping")
+//		h.AssertSentContains("Pong!")
+//	}
+package whiskercattest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// Name is the platform name of the fake platform created by New.
+const Name = "Fake"
+
+// ActionKind identifies what the bot did on the fake platform.
+type ActionKind string
+
+const (
+	ActionSend     ActionKind = "Send"
+	ActionEdit     ActionKind = "Edit"
+	ActionDelete   ActionKind = "Delete"
+	ActionReact    ActionKind = "React"
+	ActionPresence ActionKind = "Presence"
+)
+
+// Action is a single call the bot made on the fake platform.
+type Action struct {
+	Kind      ActionKind
+	ChannelID string            // Channel the action targeted
+	MessageID string            // Sent, edited, deleted or reacted-to message
+	ReplyTo   string            // Message a send or Respond replied to, if any
+	Message   types.MessageSend // Content of sends and edits
+	Emoji     string            // Reaction emoji
+	Status    platform.Status   // Presence updates
+}
+
+// Message is the types.Event.Context of injected messages.
+type Message struct {
+	ID        string
+	ChannelID string
+	ServerID  string
+	Author    types.User
+	Content   string
+	ReplyTo   string // ID of the message this one replies to
+	CreatedAt time.Time
+	EditedAt  time.Time
+}
+
+// callback returns the normalized form of m.
+func (m *Message) callback() types.MessageCallback {
+	return types.MessageCallback{
+		ID:        m.ID,
+		ChannelID: m.ChannelID,
+		ServerID:  m.ServerID,
+		Content:   m.Content,
+		Author:    m.Author,
+		CreatedAt: m.CreatedAt,
+		EditedAt:  m.EditedAt,
+		ReplyTo:   m.ReplyTo,
+		DM:        m.ServerID == "",
+	}
+}
+
+// Interaction is the types.Event.Context of injected interactions.
+type Interaction struct {
+	ID        string
+	ChannelID string
+	ServerID  string
+	Author    types.User
+	Name      string
+	Fields    map[string]string
+}
+
+// Reaction is the types.Event.Context of injected reactions.
+type Reaction struct {
+	ChannelID string
+	MessageID string
+	User      types.User
+	Emoji     string
+}
+
+// User returns a user whose ID and username are both name.
+func User(name string) types.User {
+	return types.User{ID: name, Username: name}
+}
+
+// Platform is an in-memory platform.Platform. Injected events are
+// delivered synchronously, and every send, edit, delete, reaction and
+// presence update is recorded instead of going anywhere.
+type Platform struct {
+	name  string
+	ready platform.ReadySignal
+
+	mu        sync.Mutex
+	sink      func(types.Event)
+	connected bool
+	actions   []Action
+	errs      map[ActionKind]error
+	nextID    int
+	server    string
+	connect   func(context.Context) error
+	holdReady bool
+}
+
+// NewPlatform creates a fake platform reporting itself as name.
+func NewPlatform(name string) *Platform {
+	return &Platform{name: name, errs: make(map[ActionKind]error)}
+}
+
+// Name implements platform.Platform.
+func (p *Platform) Name() string { return p.name }
+
+// Connect implements platform.Platform. It becomes ready immediately,
+// unless HoldReady was called.
+func (p *Platform) Connect(ctx context.Context) error {
+	p.mu.Lock()
+	connect := p.connect
+	p.mu.Unlock()
+	if connect != nil {
+		if err := connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	p.connected = true
+	hold := p.holdReady
+	p.mu.Unlock()
+
+	p.ready.Reset()
+	if !hold {
+		p.ready.Fire()
+	}
+	p.emitState(types.EventConnected)
+	return nil
+}
+
+// Disconnect implements platform.Platform.
+func (p *Platform) Disconnect(context.Context) error {
+	p.mu.Lock()
+	wasConnected := p.connected
+	p.connected = false
+	p.mu.Unlock()
+
+	if wasConnected {
+		p.emitState(types.EventDisconnected)
+	}
+	return nil
+}
+
+// Ready implements platform.Platform.
+func (p *Platform) Ready() <-chan struct{} { return p.ready.Done() }
+
+// Connected reports whether the platform is connected.
+func (p *Platform) Connected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connected
+}
+
+// OnConnect makes every following Connect call fn first, failing with its
+// error if it returns one. fn may block to play a slow gateway. A nil fn
+// makes Connect succeed immediately again.
+func (p *Platform) OnConnect(fn func(ctx context.Context) error) {
+	p.mu.Lock()
+	p.connect = fn
+	p.mu.Unlock()
+}
+
+// HoldReady keeps the platform from becoming ready when it connects until
+// the returned function is called.
+func (p *Platform) HoldReady() (fire func()) {
+	p.mu.Lock()
+	p.holdReady = true
+	p.mu.Unlock()
+
+	return func() {
+		p.mu.Lock()
+		p.holdReady = false
+		p.mu.Unlock()
+		p.ready.Fire()
+	}
+}
+
+// HandleEvents implements platform.Platform.
+func (p *Platform) HandleEvents(sink func(types.Event)) {
+	p.mu.Lock()
+	p.sink = sink
+	p.mu.Unlock()
+}
+
+// Send implements platform.Platform.
+func (p *Platform) Send(channelID string, msg types.MessageSend) (*types.SentMessage, error) {
+	return p.send(Action{Kind: ActionSend, ChannelID: channelID, ReplyTo: msg.ReplyTo, Message: msg})
+}
+
+// Edit implements platform.Platform.
+func (p *Platform) Edit(channelID, messageID string, msg types.MessageSend) (*types.SentMessage, error) {
+	return p.record(Action{Kind: ActionEdit, ChannelID: channelID, MessageID: messageID, Message: msg})
+}
+
+// Delete implements platform.Platform.
+func (p *Platform) Delete(channelID, messageID string) error {
+	_, err := p.record(Action{Kind: ActionDelete, ChannelID: channelID, MessageID: messageID})
+	return err
+}
+
+// React implements platform.Platform.
+func (p *Platform) React(channelID, messageID, emoji string) error {
+	_, err := p.record(Action{Kind: ActionReact, ChannelID: channelID, MessageID: messageID, Emoji: emoji})
+	return err
+}
+
+// SetPresence implements platform.Platform.
+func (p *Platform) SetPresence(status platform.Status) error {
+	_, err := p.record(Action{Kind: ActionPresence, Status: status})
+	return err
+}
+
+// Respond implements platform.Platform. Responses to injected messages
+// record the message as ReplyTo.
+func (p *Platform) Respond(e types.Event, msg types.MessageSend, edit *string) (*types.SentMessage, error) {
+	if e.ChannelID == "" {
+		return nil, fmt.Errorf("unsupported fake context %T", e.Context)
+	}
+	if edit != nil {
+		return p.Edit(e.ChannelID, *edit, msg)
+	}
+
+	action := Action{Kind: ActionSend, ChannelID: e.ChannelID, Message: msg}
+	if m, ok := e.Context.(*Message); ok {
+		action.ReplyTo = m.ID
+	}
+	return p.send(action)
+}
+
+func (p *Platform) send(action Action) (*types.SentMessage, error) {
+	p.mu.Lock()
+	p.nextID++
+	action.MessageID = "sent-" + strconv.Itoa(p.nextID)
+	p.mu.Unlock()
+	return p.record(action)
+}
+
+// record stores an action, unless Fail has armed an error for its kind.
+func (p *Platform) record(action Action) (*types.SentMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.errs[action.Kind]; err != nil {
+		return nil, err
+	}
+	p.actions = append(p.actions, action)
+	return &types.SentMessage{ID: action.MessageID, ChannelID: action.ChannelID, Platform: p.name, Raw: action}, nil
+}
+
+// Fail makes every following action of kind fail with err. A nil err
+// makes them succeed again.
+func (p *Platform) Fail(kind ActionKind, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		delete(p.errs, kind)
+	} else {
+		p.errs[kind] = err
+	}
+}
+
+// Actions returns every recorded action, in order.
+func (p *Platform) Actions() []Action {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Action(nil), p.actions...)
+}
+
+// Reset forgets the recorded actions.
+func (p *Platform) Reset() {
+	p.mu.Lock()
+	p.actions = nil
+	p.mu.Unlock()
+}
+
+// Emit delivers evt to the bot as if the platform had produced it. The
+// Platform and Session fields are filled in.
+func (p *Platform) Emit(evt types.Event) {
+	evt.Platform = p.name
+	evt.Session = p
+	if evt.Name == "" {
+		evt.Name = string(evt.Type)
+	}
+
+	p.mu.Lock()
+	sink := p.sink
+	p.mu.Unlock()
+
+	if sink != nil {
+		sink(evt)
+	}
+}
+
+func (p *Platform) emitState(eventType types.EventType) {
+	p.Emit(types.Event{Type: eventType})
+}
+
+func (p *Platform) newID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextID++
+	return "msg-" + strconv.Itoa(p.nextID)
+}
+
+// SetServer sets the server of injected events. The default, an empty
+// ID, makes them look like direct messages.
+func (p *Platform) SetServer(serverID string) {
+	p.mu.Lock()
+	p.server = serverID
+	p.mu.Unlock()
+}
+
+func (p *Platform) serverID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.server
+}
+
+// InjectMessage delivers a MessageCreate from author and returns it.
+func (p *Platform) InjectMessage(channelID string, author types.User, content string) *Message {
+	return p.injectMessage(&Message{ChannelID: channelID, Author: author, Content: content})
+}
+
+// InjectReply delivers a MessageCreate from author replying to parent and
+// returns it.
+func (p *Platform) InjectReply(parent *Message, author types.User, content string) *Message {
+	return p.injectMessage(&Message{ChannelID: parent.ChannelID, Author: author, Content: content, ReplyTo: parent.ID})
+}
+
+func (p *Platform) injectMessage(m *Message) *Message {
+	m.ID = p.newID()
+	m.ServerID = p.serverID()
+	m.CreatedAt = time.Now()
+	p.Emit(types.Event{
+		Type:      types.MessageCreate,
+		Context:   m,
+		Data:      m.callback(),
+		ChannelID: m.ChannelID,
+		ServerID:  m.ServerID,
+	})
+	return m
+}
+
+// InjectEdit delivers a MessageUpdate of a previously injected message.
+func (p *Platform) InjectEdit(m *Message, content string) {
+	edited := *m
+	edited.Content = content
+	edited.EditedAt = time.Now()
+	p.Emit(types.Event{
+		Type:      types.MessageUpdate,
+		Context:   &edited,
+		Data:      edited.callback(),
+		ChannelID: m.ChannelID,
+		ServerID:  m.ServerID,
+	})
+}
+
+// InjectDelete delivers a MessageDelete of a previously injected message.
+func (p *Platform) InjectDelete(m *Message) {
+	p.Emit(types.Event{
+		Type:      types.MessageDelete,
+		Context:   m,
+		Data:      m.callback(),
+		ChannelID: m.ChannelID,
+		ServerID:  m.ServerID,
+	})
+}
+
+// InjectInteraction delivers an InteractionCreate for command name.
+func (p *Platform) InjectInteraction(channelID string, author types.User, name string, fields map[string]string) *Interaction {
+	if fields == nil {
+		fields = make(map[string]string)
+	}
+	i := &Interaction{ID: p.newID(), ChannelID: channelID, ServerID: p.serverID(), Author: author, Name: name, Fields: fields}
+	p.Emit(types.Event{
+		Type:      types.InteractionCreate,
+		Context:   i,
+		Data:      types.InteractionCallback{Name: name, Fields: fields, Author: author},
+		ChannelID: channelID,
+		ServerID:  i.ServerID,
+	})
+	return i
+}
+
+// InjectReaction delivers a ReactionAdd, or a ReactionRemove when removed
+// is true, of the unicode emoji.
+func (p *Platform) InjectReaction(channelID, messageID string, user types.User, emoji string, removed bool) {
+	eventType := types.ReactionAdd
+	if removed {
+		eventType = types.ReactionRemove
+	}
+	serverID := p.serverID()
+	p.Emit(types.Event{
+		Type:    eventType,
+		Context: &Reaction{ChannelID: channelID, MessageID: messageID, User: user, Emoji: emoji},
+		Data: types.ReactionCallback{
+			MessageID: messageID,
+			ChannelID: channelID,
+			ServerID:  serverID,
+			User:      user,
+			Emoji:     types.Emoji{Name: emoji},
+		},
+		ChannelID: channelID,
+		ServerID:  serverID,
+	})
+}
+
+// InjectJoin delivers an EventMemberJoin for user.
+func (p *Platform) InjectJoin(user types.User) {
+	serverID := p.serverID()
+	member := types.MemberCallback{User: user, ServerID: serverID, JoinedAt: time.Now()}
+	p.Emit(types.Event{Type: types.EventMemberJoin, Context: user, Data: member, ServerID: serverID})
+}
+
+// InjectLeave delivers an EventMemberLeave for user.
+func (p *Platform) InjectLeave(user types.User) {
+	serverID := p.serverID()
+	member := types.MemberCallback{User: user, ServerID: serverID}
+	p.Emit(types.Event{Type: types.EventMemberLeave, Context: user, Data: member, ServerID: serverID})
+}
@@ -0,0 +1,267 @@
+package whiskercattest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luvixsocial/whiskercat/platform"
+	"github.com/luvixsocial/whiskercat/types"
+)
+
+// collect installs a sink on p that records every event.
+func collect(p *Platform) *[]types.Event {
+	var events []types.Event
+	p.HandleEvents(func(evt types.Event) { events = append(events, evt) })
+	return &events
+}
+
+func TestConnect(t *testing.T) {
+	p := NewPlatform("Fake")
+	events := collect(p)
+
+	if err := p.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-p.Ready():
+	default:
+		t.Error("Ready did not fire on Connect")
+	}
+	if err := p.Disconnect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// A second Disconnect has nothing to report.
+	p.Disconnect(context.Background())
+
+	var got []types.EventType
+	for _, evt := range *events {
+		got = append(got, evt.Type)
+	}
+	want := []types.EventType{types.EventConnected, types.EventDisconnected}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestInject(t *testing.T) {
+	alice := User("alice")
+	tests := []struct {
+		name   string
+		server string
+		inject func(p *Platform)
+		want   types.EventType
+		check  func(t *testing.T, evt types.Event)
+	}{
+		{
+			name:   "message",
+			inject: func(p *Platform) { p.InjectMessage("general", alice, "hi") },
+			want:   types.MessageCreate,
+			check: func(t *testing.T, evt types.Event) {
+				msg := evt.Data.(types.MessageCallback)
+				if msg.ID == "" || msg.Content != "hi" || msg.Author != alice || !msg.DM || msg.CreatedAt.IsZero() {
+					t.Errorf("message = %+v", msg)
+				}
+				if m, ok := evt.Context.(*Message); !ok || m.ID != msg.ID {
+					t.Errorf("Context = %#v, want the injected *Message", evt.Context)
+				}
+			},
+		},
+		{
+			name:   "message in a server",
+			server: "guild",
+			inject: func(p *Platform) { p.InjectMessage("general", alice, "hi") },
+			want:   types.MessageCreate,
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.DM || msg.ServerID != "guild" || evt.ServerID != "guild" {
+					t.Errorf("message = %+v in server %q, want guild", msg, evt.ServerID)
+				}
+			},
+		},
+		{
+			name: "reply",
+			inject: func(p *Platform) {
+				parent := &Message{ID: "parent", ChannelID: "general"}
+				p.InjectReply(parent, alice, "re")
+			},
+			want: types.MessageCreate,
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.ReplyTo != "parent" {
+					t.Errorf("ReplyTo = %q, want parent", msg.ReplyTo)
+				}
+			},
+		},
+		{
+			name:   "edit",
+			inject: func(p *Platform) { p.InjectEdit(&Message{ID: "1", ChannelID: "general", Content: "old"}, "new") },
+			want:   types.MessageUpdate,
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.ID != "1" || msg.Content != "new" || msg.EditedAt.IsZero() {
+					t.Errorf("edit = %+v", msg)
+				}
+			},
+		},
+		{
+			name:   "delete",
+			inject: func(p *Platform) { p.InjectDelete(&Message{ID: "1", ChannelID: "general"}) },
+			want:   types.MessageDelete,
+			check: func(t *testing.T, evt types.Event) {
+				if msg := evt.Data.(types.MessageCallback); msg.ID != "1" {
+					t.Errorf("deleted %q, want 1", msg.ID)
+				}
+			},
+		},
+		{
+			name:   "interaction",
+			inject: func(p *Platform) { p.InjectInteraction("general", alice, "ping", nil) },
+			want:   types.InteractionCreate,
+			check: func(t *testing.T, evt types.Event) {
+				if i := evt.Data.(types.InteractionCallback); i.Name != "ping" || i.Fields == nil || i.Author != alice {
+					t.Errorf("interaction = %+v", i)
+				}
+			},
+		},
+		{
+			name:   "reaction",
+			inject: func(p *Platform) { p.InjectReaction("general", "1", alice, "👍", false) },
+			want:   types.ReactionAdd,
+			check: func(t *testing.T, evt types.Event) {
+				if r := evt.Data.(types.ReactionCallback); r.MessageID != "1" || r.Emoji.Name != "👍" || r.User != alice {
+					t.Errorf("reaction = %+v", r)
+				}
+			},
+		},
+		{
+			name:   "removed reaction",
+			inject: func(p *Platform) { p.InjectReaction("general", "1", alice, "👍", true) },
+			want:   types.ReactionRemove,
+		},
+		{
+			name:   "join",
+			server: "guild",
+			inject: func(p *Platform) { p.InjectJoin(alice) },
+			want:   types.EventMemberJoin,
+			check: func(t *testing.T, evt types.Event) {
+				if m := evt.Data.(types.MemberCallback); m.User != alice || m.ServerID != "guild" || m.JoinedAt.IsZero() {
+					t.Errorf("member = %+v", m)
+				}
+			},
+		},
+		{
+			name:   "leave",
+			server: "guild",
+			inject: func(p *Platform) { p.InjectLeave(alice) },
+			want:   types.EventMemberLeave,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlatform("Fake")
+			p.SetServer(tt.server)
+			events := collect(p)
+
+			tt.inject(p)
+
+			if len(*events) != 1 {
+				t.Fatalf("delivered %d events, want 1", len(*events))
+			}
+			evt := (*events)[0]
+			if evt.Type != tt.want || evt.Name != string(tt.want) {
+				t.Errorf("event %s (%s), want %s", evt.Type, evt.Name, tt.want)
+			}
+			if evt.Platform != "Fake" || evt.Session != p {
+				t.Errorf("event from %q with session %v, want the fake platform", evt.Platform, evt.Session)
+			}
+			if tt.check != nil {
+				tt.check(t, evt)
+			}
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	p := NewPlatform("Fake")
+
+	sent, err := p.Send("general", types.MessageSend{Content: "hi", ReplyTo: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.ID == "" || sent.ChannelID != "general" || sent.Platform != "Fake" {
+		t.Errorf("sent = %+v", sent)
+	}
+	p.Edit("general", sent.ID, types.MessageSend{Content: "hello"})
+	p.React("general", sent.ID, "👍")
+	p.Delete("general", sent.ID)
+	p.SetPresence(platform.Status{Name: "tests"})
+
+	want := []Action{
+		{Kind: ActionSend, ChannelID: "general", MessageID: sent.ID, ReplyTo: "1", Message: types.MessageSend{Content: "hi", ReplyTo: "1"}},
+		{Kind: ActionEdit, ChannelID: "general", MessageID: sent.ID, Message: types.MessageSend{Content: "hello"}},
+		{Kind: ActionReact, ChannelID: "general", MessageID: sent.ID, Emoji: "👍"},
+		{Kind: ActionDelete, ChannelID: "general", MessageID: sent.ID},
+		{Kind: ActionPresence, Status: platform.Status{Name: "tests"}},
+	}
+	got := p.Actions()
+	if len(got) != len(want) {
+		t.Fatalf("recorded %d actions, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("action %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	p.Reset()
+	if got := p.Actions(); len(got) != 0 {
+		t.Errorf("Reset kept %d actions", len(got))
+	}
+}
+
+func TestFail(t *testing.T) {
+	p := NewPlatform("Fake")
+	failure := errors.New("missing permissions")
+
+	p.Fail(ActionSend, failure)
+	if _, err := p.Send("general", types.MessageSend{Content: "hi"}); !errors.Is(err, failure) {
+		t.Errorf("Send err = %v, want %v", err, failure)
+	}
+	if err := p.React("general", "1", "👍"); err != nil {
+		t.Errorf("React err = %v; only sends should fail", err)
+	}
+
+	p.Fail(ActionSend, nil)
+	if _, err := p.Send("general", types.MessageSend{Content: "hi"}); err != nil {
+		t.Errorf("Send err = %v after clearing the failure", err)
+	}
+	if n := len(p.Actions()); n != 2 {
+		t.Errorf("recorded %d actions, want 2: failed actions are not recorded", n)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	p := NewPlatform("Fake")
+	var evt types.Event
+	p.HandleEvents(func(e types.Event) { evt = e })
+	m := p.InjectMessage("general", User("alice"), "ping")
+
+	sent, err := p.Respond(evt, types.MessageSend{Content: "pong"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := p.Actions()[0]; a.Kind != ActionSend || a.ReplyTo != m.ID || a.ChannelID != "general" {
+		t.Errorf("response = %+v, want a reply to %s", a, m.ID)
+	}
+
+	edit := sent.ID
+	if _, err := p.Respond(evt, types.MessageSend{Content: "pong!"}, &edit); err != nil {
+		t.Fatal(err)
+	}
+	if a := p.Actions()[1]; a.Kind != ActionEdit || a.MessageID != sent.ID {
+		t.Errorf("edit = %+v, want an edit of %s", a, sent.ID)
+	}
+
+	if _, err := p.Respond(types.Event{}, types.MessageSend{Content: "?"}, nil); err == nil {
+		t.Error("Respond to an event without a channel succeeded")
+	}
+}